@@ -0,0 +1,225 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+/*
+	os.go 的 Signal() 手工把信号映射成退出码，还监听了无法捕获的 SIGKILL。
+	Manager 把信号变成根 context 的取消：
+		1.收到第一个信号（或调用 Shutdown、parent 结束）时取消 Context()，Cause 为 *SignalError；
+		2.Wait 等到取消后按注册的逆序执行关闭钩子，后启动的先关闭；每个钩子有自己的超时，
+		  超时的钩子不再等待，继续执行下一个；
+		3.关闭期间再收到信号时强制退出，不再等待剩下的钩子；
+		4.Wait 返回每个钩子的结果，main 用 Report.ExitCode() 退出。
+	SIGKILL 和 SIGSTOP 不能被捕获，不要放进 Signals。
+*/
+
+// DefaultSignals 是默认监听的信号
+var DefaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP}
+
+// Options 是 Manager 的配置
+type Options struct {
+	Signals     []os.Signal    // 监听的信号，nil 时为 DefaultSignals
+	HookTimeout time.Duration  // 钩子的默认超时，<= 0 时为 10 秒
+	Exit        func(code int) // 强制退出，nil 时为 os.Exit，测试中替换
+}
+
+// Hook 是关闭钩子
+type Hook struct {
+	Name    string
+	Timeout time.Duration
+	Fn      func(ctx context.Context) error
+}
+
+// HookResult 是一个钩子的执行结果
+type HookResult struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+	TimedOut bool // 超时后不再等待，钩子可能仍在后台运行
+}
+
+func (r HookResult) String() string {
+	switch {
+	case r.TimedOut:
+		return fmt.Sprintf("%s: timed out after %v", r.Name, r.Duration.Round(time.Millisecond))
+	case r.Err != nil:
+		return fmt.Sprintf("%s: %v (%v)", r.Name, r.Err, r.Duration.Round(time.Millisecond))
+	default:
+		return fmt.Sprintf("%s: ok (%v)", r.Name, r.Duration.Round(time.Millisecond))
+	}
+}
+
+// Report 是关闭的结果
+type Report struct {
+	Cause   error        // 关闭的原因，收到信号时为 *SignalError
+	Results []HookResult // 按执行顺序，即注册的逆序
+}
+
+// Err 合并所有失败或超时的钩子，全部成功时返回 nil
+func (r Report) Err() error {
+	var errs []error
+	for _, h := range r.Results {
+		switch {
+		case h.TimedOut:
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name, context.DeadlineExceeded))
+		case h.Err != nil:
+			errs = append(errs, fmt.Errorf("%s: %w", h.Name, h.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ExitCode 全部钩子成功时为 0，否则为 1
+func (r Report) ExitCode() int {
+	if r.Err() != nil {
+		return 1
+	}
+	return 0
+}
+
+// SignalError 是收到信号时根 context 的 Cause
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return "received signal " + e.Signal.String()
+}
+
+// Manager 管理进程的关闭，请用 New 创建
+type Manager struct {
+	ctx     context.Context
+	cancel  context.CancelCauseFunc
+	timeout time.Duration
+	exit    func(int)
+	sigCh   chan os.Signal
+	stop    chan struct{}
+
+	mu     sync.Mutex
+	hooks  []Hook
+	once   sync.Once
+	report Report
+}
+
+// New 开始监听信号，返回的 Manager 必须调用 Wait
+func New(parent context.Context, opts *Options) *Manager {
+	var o Options
+	if opts != nil {
+		o = *opts
+	}
+	if o.Signals == nil {
+		o.Signals = DefaultSignals
+	}
+	if o.HookTimeout <= 0 {
+		o.HookTimeout = 10 * time.Second
+	}
+	if o.Exit == nil {
+		o.Exit = os.Exit
+	}
+	m := &Manager{
+		timeout: o.HookTimeout,
+		exit:    o.Exit,
+		sigCh:   make(chan os.Signal, 1),
+		stop:    make(chan struct{}),
+	}
+	m.ctx, m.cancel = context.WithCancelCause(parent)
+	signal.Notify(m.sigCh, o.Signals...)
+	go m.watch()
+	return m
+}
+
+// watch 第一个信号取消根 context，第二个信号强制退出
+func (m *Manager) watch() {
+	select {
+	case sig := <-m.sigCh:
+		m.cancel(&SignalError{Signal: sig})
+	case <-m.ctx.Done():
+	case <-m.stop:
+		return
+	}
+	select {
+	case sig := <-m.sigCh:
+		m.exit(exitCode(sig))
+	case <-m.stop:
+	}
+}
+
+// exitCode 按 shell 的习惯，被信号终止时退出码为 128+信号值
+func exitCode(sig os.Signal) int {
+	if s, ok := sig.(syscall.Signal); ok {
+		return 128 + int(s)
+	}
+	return 1
+}
+
+// Context 返回根 context，关闭开始时被取消
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// OnShutdown 注册关闭钩子，timeout <= 0 时使用 Options.HookTimeout
+func (m *Manager) OnShutdown(name string, timeout time.Duration, fn func(ctx context.Context) error) {
+	if timeout <= 0 {
+		timeout = m.timeout
+	}
+	m.mu.Lock()
+	m.hooks = append(m.hooks, Hook{Name: name, Timeout: timeout, Fn: fn})
+	m.mu.Unlock()
+}
+
+// Shutdown 不等信号，主动开始关闭
+func (m *Manager) Shutdown(cause error) {
+	m.cancel(cause)
+}
+
+// Wait 等到关闭开始，逆序执行钩子并返回结果；钩子只执行一次，多次调用返回同一个结果
+func (m *Manager) Wait() Report {
+	<-m.ctx.Done()
+	m.once.Do(func() {
+		rep := &m.report
+		rep.Cause = context.Cause(m.ctx)
+		m.mu.Lock()
+		hooks := append([]Hook(nil), m.hooks...)
+		m.mu.Unlock()
+		// 根 context 已经取消，钩子使用不会被取消、只带超时和原有值的 context
+		base := context.WithoutCancel(m.ctx)
+		for i := len(hooks) - 1; i >= 0; i-- {
+			rep.Results = append(rep.Results, runHook(base, hooks[i]))
+		}
+		close(m.stop)
+		signal.Stop(m.sigCh)
+	})
+	return m.report
+}
+
+func runHook(base context.Context, h Hook) HookResult {
+	ctx, cancel := context.WithTimeout(base, h.Timeout)
+	defer cancel()
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		done <- h.Fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		// 遵守 ctx 的钩子在超时时返回 DeadlineExceeded，同样算作超时
+		timedOut := ctx.Err() != nil && errors.Is(err, context.DeadlineExceeded)
+		return HookResult{Name: h.Name, Err: err, Duration: time.Since(start), TimedOut: timedOut}
+	case <-ctx.Done():
+		return HookResult{Name: h.Name, Err: ctx.Err(), Duration: time.Since(start), TimedOut: true}
+	}
+}
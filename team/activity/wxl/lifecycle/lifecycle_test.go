@@ -0,0 +1,129 @@
+//go:build unix
+
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// 测试向自己发送 SIGUSR1；只能在 Manager 监听期间发送，否则默认行为会结束测试进程
+func newTestManager(parent context.Context, exit func(int)) *Manager {
+	return New(parent, &Options{Signals: []os.Signal{syscall.SIGUSR1}, HookTimeout: time.Second, Exit: exit})
+}
+
+func kill(t *testing.T) {
+	t.Helper()
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignalRunsHooksInReverse(t *testing.T) {
+	m := newTestManager(context.Background(), func(code int) { t.Errorf("unexpected force exit %d", code) })
+	var order []string
+	for _, name := range []string{"db", "cache", "http"} {
+		m.OnShutdown(name, 0, func(ctx context.Context) error {
+			if ctx.Err() != nil {
+				t.Errorf("hook %s got a canceled ctx", name)
+			}
+			order = append(order, name)
+			return nil
+		})
+	}
+
+	kill(t)
+	rep := m.Wait()
+	if want := []string{"http", "cache", "db"}; !slices.Equal(order, want) {
+		t.Errorf("order = %v, want %v", order, want)
+	}
+	var se *SignalError
+	if !errors.As(rep.Cause, &se) || se.Signal != syscall.SIGUSR1 {
+		t.Errorf("Cause = %v, want SIGUSR1", rep.Cause)
+	}
+	if !errors.As(context.Cause(m.Context()), &se) {
+		t.Errorf("Context cause = %v", context.Cause(m.Context()))
+	}
+	if rep.ExitCode() != 0 || rep.Err() != nil || len(rep.Results) != 3 {
+		t.Errorf("report = %+v", rep)
+	}
+	if again := m.Wait(); len(again.Results) != 3 {
+		t.Error("second Wait should return the same report")
+	}
+}
+
+func TestHookFailures(t *testing.T) {
+	m := newTestManager(context.Background(), nil)
+	release := make(chan struct{})
+	defer close(release)
+	ran := false
+	m.OnShutdown("last", 0, func(context.Context) error { ran = true; return nil })
+	m.OnShutdown("stuck", 20*time.Millisecond, func(context.Context) error { <-release; return nil })
+	m.OnShutdown("polite", 20*time.Millisecond, func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() })
+	m.OnShutdown("panics", 0, func(context.Context) error { panic("boom") })
+	m.OnShutdown("fails", 0, func(context.Context) error { return errors.New("flush failed") })
+
+	stopping := errors.New("deploy")
+	m.Shutdown(stopping)
+	rep := m.Wait()
+	if rep.Cause != stopping || !ran {
+		t.Fatalf("Cause = %v, last hook ran = %t", rep.Cause, ran)
+	}
+	got := make([]string, len(rep.Results))
+	for i, r := range rep.Results {
+		got[i] = r.String()
+	}
+	for i, want := range []string{"fails: flush failed", "panics: panic: boom", "polite: timed out", "stuck: timed out", "last: ok"} {
+		if !strings.HasPrefix(got[i], want) {
+			t.Errorf("result %d = %q, want prefix %q", i, got[i], want)
+		}
+	}
+	if rep.ExitCode() != 1 || !errors.Is(rep.Err(), context.DeadlineExceeded) {
+		t.Errorf("ExitCode = %d, Err = %v", rep.ExitCode(), rep.Err())
+	}
+}
+
+func TestSecondSignalForcesExit(t *testing.T) {
+	code := 0
+	forced := make(chan struct{})
+	m := newTestManager(context.Background(), func(c int) { code = c; close(forced) })
+	started := make(chan struct{})
+	// 钩子一直等到强制退出，保证第二个信号到达时仍在监听
+	m.OnShutdown("slow", 5*time.Second, func(ctx context.Context) error {
+		close(started)
+		select {
+		case <-forced:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+
+	waited := make(chan Report)
+	go func() { waited <- m.Wait() }()
+	kill(t)
+	<-started
+	kill(t)
+	rep := <-waited
+	if rep.Err() != nil {
+		t.Fatalf("second signal did not force exit: %v", rep.Err())
+	}
+	if code != 128+int(syscall.SIGUSR1) {
+		t.Errorf("exit code = %d, want %d", code, 128+int(syscall.SIGUSR1))
+	}
+}
+
+func TestParentCancel(t *testing.T) {
+	parent, cancel := context.WithCancel(context.Background())
+	m := newTestManager(parent, nil)
+	cancel()
+	if rep := m.Wait(); !errors.Is(rep.Cause, context.Canceled) {
+		t.Errorf("Cause = %v, want Canceled", rep.Cause)
+	}
+}
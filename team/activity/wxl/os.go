@@ -3,16 +3,17 @@ package main
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"os/exec"
-	"os/signal"
 	"runtime"
 	"strconv"
-	"syscall"
 	"time"
+
+	"github.com/ZcmOrg/demo-go-base/team/activity/wxl/lifecycle"
 )
 
 func main() {
@@ -84,43 +85,20 @@ func GetPid() {
 
 func Signal() {
 
-	sChan := make(chan os.Signal, 1)
-
-	signal.Notify(sChan,
-		syscall.SIGHUP,
-		syscall.SIGINT,
-		syscall.SIGTERM,
-		syscall.SIGQUIT,
-		syscall.SIGKILL)
+	// SIGKILL 不能被捕获，信号由 lifecycle 转成根 context 的取消，再按逆序执行关闭钩子
+	m := lifecycle.New(context.Background(), nil)
 
-	exitChan := make(chan int)
-	go func() {
-		signal := <-sChan
-		switch signal {
-		case syscall.SIGHUP:
-			fmt.Println("closed")
-			exitChan <- 0
-
-		case syscall.SIGINT:
-			fmt.Println(" CTRL+C")
-			exitChan <- 1
-
-		case syscall.SIGTERM:
-			fmt.Println("kill SIGTERM  executed")
-			exitChan <- 1
-
-		case syscall.SIGKILL:
-			fmt.Println("SIGKILL handler")
-			exitChan <- 1
-
-		case syscall.SIGQUIT:
-			fmt.Println("kill SIGQUIT  executed ")
-			exitChan <- 1
-		}
-	}()
+	m.OnShutdown("close log", 5*time.Second, func(ctx context.Context) error {
+		fmt.Println("closed")
+		return nil
+	})
 
-	code := <-exitChan
-	os.Exit(code)
+	rep := m.Wait()
+	fmt.Println(rep.Cause)
+	for _, r := range rep.Results {
+		fmt.Println(r)
+	}
+	os.Exit(rep.ExitCode())
 }
 
 func osExec() {
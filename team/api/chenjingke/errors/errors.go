@@ -3,8 +3,7 @@ package errors
 import (
 	"errors"
 	"fmt"
-
-	"github.com/astaxie/beego"
+	"log/slog"
 )
 
 func main() {
@@ -18,7 +17,7 @@ func main() {
 	// 例子：
 	err := errors.New("标的类型有误")
 	if err != nil {
-		beego.Info(err)
+		slog.Info("标的校验失败", "err", err)
 	}
 	// 延伸1：fmt包有个返回error类型的：内部也是调用errors包的New方法
 	/*
@@ -43,5 +42,7 @@ func main() {
 		sql := ` select * from cg_trade where trade_uuid =?  `
 		_, err = o.Raw(sql, tradeUuid).QueryRows(&cgtrades)
 		若cgtrades值为空，err不会返回错误，是返回nil
+
+		两种查询的表现不一致，可以用 TranslateDB(err) 统一归类，之后 errors.Is(err, ErrNotFound) 判断即可（见 sqlerr.go）
 	*/
 }
@@ -0,0 +1,53 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+// With 给 err 附加键值对形式的上下文，如 With(err, "product_code", code)，
+// 奇数个参数时最后一个的键为 "!BADKEY"，err 为 nil 时返回 nil
+func With(err error, kv ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	e := annotate(err, 1)
+	fields := make(map[string]interface{}, len(e.fields)+len(kv)/2)
+	for k, v := range e.fields {
+		fields[k] = v
+	}
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields["!BADKEY"] = kv[i]
+			break
+		}
+		fields[fmt.Sprint(kv[i])] = kv[i+1]
+	}
+	e.fields = fields
+	return e
+}
+
+// Fields 返回该层附加的上下文
+func (e *Error) Fields() map[string]interface{} {
+	return e.fields
+}
+
+// FieldsOf 合并错误链上所有层的上下文，外层覆盖内层的同名键
+func FieldsOf(err error) map[string]interface{} {
+	var fields map[string]interface{}
+	for ; err != nil; err = errors.Unwrap(err) {
+		e, ok := err.(*Error)
+		if !ok {
+			continue
+		}
+		for k, v := range e.fields {
+			if fields == nil {
+				fields = make(map[string]interface{})
+			}
+			if _, ok := fields[k]; !ok {
+				fields[k] = v
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,122 @@
+package errors
+
+import (
+	"errors"
+)
+
+// Kind 是错误的类别，调用方按类别判断而不必关心错误来自哪一层
+type Kind uint8
+
+const (
+	KindUnknown Kind = iota
+	KindInvalid
+	KindNotFound
+	KindConflict
+	KindPermission
+	KindUnauthenticated
+	KindCanceled
+	KindDeadlineExceeded
+	KindUnavailable
+	KindInternal
+)
+
+var kindNames = [...]string{
+	KindUnknown:          "unknown",
+	KindInvalid:          "invalid",
+	KindNotFound:         "not_found",
+	KindConflict:         "conflict",
+	KindPermission:       "permission_denied",
+	KindUnauthenticated:  "unauthenticated",
+	KindCanceled:         "canceled",
+	KindDeadlineExceeded: "deadline_exceeded",
+	KindUnavailable:      "unavailable",
+	KindInternal:         "internal",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return "unknown"
+}
+
+// Error 让 Kind 本身可以作为哨兵错误使用
+func (k Kind) Error() string {
+	return k.String()
+}
+
+// 各类别对应的哨兵错误，用 errors.Is(err, ErrNotFound) 判断
+var (
+	ErrInvalid          error = KindInvalid
+	ErrNotFound         error = KindNotFound
+	ErrConflict         error = KindConflict
+	ErrPermission       error = KindPermission
+	ErrUnauthenticated  error = KindUnauthenticated
+	ErrCanceled         error = KindCanceled
+	ErrDeadlineExceeded error = KindDeadlineExceeded
+	ErrUnavailable      error = KindUnavailable
+	ErrInternal         error = KindInternal
+)
+
+// Kind 返回该层错误的类别
+func (e *Error) Kind() Kind {
+	return e.kind
+}
+
+// Is 使 errors.Is(err, ErrNotFound) 这类判断按类别匹配
+func (e *Error) Is(target error) bool {
+	k, ok := target.(Kind)
+	return ok && k != KindUnknown && e.kind == k
+}
+
+// KindOf 返回错误链上第一个非 KindUnknown 的类别；
+// 和 errors.Is 一样按深度优先遍历 errors.Join、MultiError 等包含多个错误的节点
+func KindOf(err error) Kind {
+	for err != nil {
+		switch e := err.(type) {
+		case Kind:
+			return e
+		case *Error:
+			if e.kind != KindUnknown {
+				return e.kind
+			}
+		case interface{ Unwrap() []error }:
+			for _, err := range e.Unwrap() {
+				if k := KindOf(err); k != KindUnknown {
+					return k
+				}
+			}
+			return KindUnknown
+		}
+		err = errors.Unwrap(err)
+	}
+	return KindUnknown
+}
+
+// E 创建一个指定类别的错误
+func E(kind Kind, text string) error {
+	return &Error{msg: text, kind: kind, stack: callers(1)}
+}
+
+// WithKind 给 err 标上类别，err 为 nil 时返回 nil
+func WithKind(err error, kind Kind) error {
+	return withKind(err, kind, 2)
+}
+
+func withKind(err error, kind Kind, skip int) error {
+	if err == nil {
+		return nil
+	}
+	e := annotate(err, skip)
+	e.kind = kind
+	return e
+}
+
+// annotate 返回可以修改的 err：*Error 做一次浅拷贝，其它错误包一层并记录调用位置
+func annotate(err error, skip int) *Error {
+	if e, ok := err.(*Error); ok {
+		c := *e
+		return &c
+	}
+	return &Error{msg: err.Error(), cause: err, inline: true, stack: callers(skip + 1)}
+}
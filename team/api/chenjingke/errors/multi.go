@@ -0,0 +1,158 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+)
+
+/*
+	批量操作需要一次返回多个错误。MultiError 用来收集这些错误：
+		1.Append 可以在多个 goroutine 里并发调用；
+		2.实现了 Unwrap() []error，和 errors.Join 一样可以用 errors.Is/errors.As 检查其中任意一个；
+		3.追加 errors.Join 的结果或另一个 MultiError 时会展开成单个错误；
+		4.类别和文本都相同的错误只保留第一个；
+		5.MarshalJSON 输出 [{kind, message, fields, cause}]，HTTP 层可以直接作为响应体。
+	零值可以直接使用。
+*/
+
+// MultiError 是并发安全的错误收集器
+type MultiError struct {
+	mu   sync.Mutex
+	errs []error
+	seen map[string]struct{}
+}
+
+// Append 追加错误，nil 会被忽略
+func (m *MultiError) Append(errs ...error) {
+	// 展开和计算文本都会调用其它 MultiError 的方法，要在加锁之前完成，
+	// 否则 a.Append(&b) 和 b.Append(&a) 同时执行时会互相等待对方的锁
+	var items []item
+	for _, err := range errs {
+		items = m.flatten(items, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, it := range items {
+		if _, ok := m.seen[it.key]; ok {
+			continue
+		}
+		if m.seen == nil {
+			m.seen = make(map[string]struct{})
+		}
+		m.seen[it.key] = struct{}{}
+		m.errs = append(m.errs, it.err)
+	}
+}
+
+// item 是待追加的单个错误，key 用于去重
+type item struct {
+	err error
+	key string
+}
+
+func (m *MultiError) flatten(items []item, err error) []item {
+	if err == nil {
+		return items
+	}
+	if mm, ok := err.(*MultiError); ok {
+		if mm == m {
+			return items
+		}
+		for _, e := range mm.Errors() {
+			items = m.flatten(items, e)
+		}
+		return items
+	}
+	if errs, ok := joined(err); ok {
+		for _, e := range errs {
+			items = m.flatten(items, e)
+		}
+		return items
+	}
+	return append(items, item{err, KindOf(err).String() + "\x00" + err.Error()})
+}
+
+// joined 判断 err 是否只是把多个错误拼在一起（errors.Join 的结果），
+// fmt.Errorf 里用多个 %w 得到的错误带有自己的文本，不会被展开
+func joined(err error) ([]error, bool) {
+	u, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return nil, false
+	}
+	errs := u.Unwrap()
+	msgs := make([]string, 0, len(errs))
+	for _, e := range errs {
+		if e != nil {
+			msgs = append(msgs, e.Error())
+		}
+	}
+	return errs, err.Error() == strings.Join(msgs, "\n")
+}
+
+// Len 返回已收集的错误个数
+func (m *MultiError) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.errs)
+}
+
+// Errors 返回已收集错误的副本
+func (m *MultiError) Errors() []error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]error(nil), m.errs...)
+}
+
+// ErrorOrNil 没有收集到错误时返回 nil，避免把空的 *MultiError 当成非 nil 的 error 返回
+func (m *MultiError) ErrorOrNil() error {
+	if m == nil || m.Len() == 0 {
+		return nil
+	}
+	return m
+}
+
+// Error 与 errors.Join 一致，每个错误一行
+func (m *MultiError) Error() string {
+	errs := m.Errors()
+	msgs := make([]string, len(errs))
+	for i, err := range errs {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap 供 errors.Is/errors.As 遍历
+func (m *MultiError) Unwrap() []error {
+	return m.Errors()
+}
+
+// ErrorDetail 是单个错误的 JSON 表示，MultiError 和 Problem 中使用
+type ErrorDetail struct {
+	Kind    string                 `json:"kind"`
+	Message string                 `json:"message"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Cause   string                 `json:"cause,omitempty"`
+}
+
+func toJSON(err error) ErrorDetail {
+	j := ErrorDetail{Kind: KindOf(err).String(), Message: err.Error(), Fields: FieldsOf(err)}
+	if e, ok := err.(*Error); ok && !e.inline {
+		j.Message = e.msg
+	}
+	if cause := errors.Unwrap(err); cause != nil {
+		j.Cause = cause.Error()
+	}
+	return j
+}
+
+// MarshalJSON 输出 [{kind, message, fields, cause}]
+func (m *MultiError) MarshalJSON() ([]byte, error) {
+	errs := m.Errors()
+	out := make([]ErrorDetail, len(errs))
+	for i, err := range errs {
+		out[i] = toJSON(err)
+	}
+	return json.Marshal(out)
+}
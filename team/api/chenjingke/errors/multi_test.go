@@ -0,0 +1,107 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMultiErrorAppend(t *testing.T) {
+	var m MultiError
+	if m.ErrorOrNil() != nil {
+		t.Fatal("empty MultiError is not nil")
+	}
+	m.Append(nil, io.EOF, E(KindNotFound, "no product"))
+	m.Append(errors.Join(io.EOF, io.ErrUnexpectedEOF))
+	m.Append(fmt.Errorf("read: %w, %w", io.EOF, io.ErrClosedPipe))
+	m.Append(&m)
+
+	if got, want := m.Len(), 4; got != want {
+		t.Fatalf("Len = %d, want %d: %q", got, want, m.Error())
+	}
+	err := m.ErrorOrNil()
+	for _, target := range []error{io.EOF, io.ErrUnexpectedEOF, io.ErrClosedPipe, ErrNotFound} {
+		if !errors.Is(err, target) {
+			t.Errorf("errors.Is(m, %v) = false", target)
+		}
+	}
+	if got, want := err.Error(), errors.Join(m.Errors()...).Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestMultiErrorConcurrent(t *testing.T) {
+	var m MultiError
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.Append(fmt.Errorf("item %d", i%10))
+		}(i)
+	}
+	wg.Wait()
+	if got := m.Len(); got != 10 {
+		t.Errorf("Len = %d, want 10", got)
+	}
+}
+
+func TestMultiErrorAppendEachOther(t *testing.T) {
+	var a, b MultiError
+	a.Append(errors.New("a"))
+	b.Append(errors.New("b"))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10000; i++ {
+				a.Append(&b)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 10000; i++ {
+				b.Append(&a)
+			}
+		}()
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a.Append(&b) and b.Append(&a) deadlocked")
+	}
+	if a.Len() != 2 || b.Len() != 2 {
+		t.Errorf("Len = %d, %d, want 2, 2", a.Len(), b.Len())
+	}
+}
+
+func TestMultiErrorJSON(t *testing.T) {
+	var m MultiError
+	m.Append(With(Wrap(E(KindNotFound, "no row"), "load product"), "product_code", "P01"))
+	m.Append(io.EOF)
+	b, err := json.Marshal(&m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"kind":"not_found","message":"load product","fields":{"product_code":"P01"},"cause":"no row"},` +
+		`{"kind":"unknown","message":"EOF"}]`
+	if string(b) != want {
+		t.Errorf("MarshalJSON =\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestFieldsOf(t *testing.T) {
+	err := With(Wrap(With(New("a"), "id", 1, "name", "x"), "b"), "id", 2, "odd")
+	got := FieldsOf(err)
+	if len(got) != 3 || got["id"] != 2 || got["name"] != "x" || got["!BADKEY"] != "odd" {
+		t.Errorf("FieldsOf = %v", got)
+	}
+}
@@ -0,0 +1,76 @@
+package errors
+
+import (
+	"errors"
+	"log/slog"
+	"sort"
+	"strconv"
+)
+
+/*
+	和 log/slog 对接：*Error 和 *MultiError 实现了 slog.LogValuer，
+	slog.Any("err", err) 输出的是一个分组，带上 msg、kind、fields 和 stack，
+	结构化日志（如 slog.NewJSONHandler）里可以直接按这些字段检索。
+	被 fmt.Errorf 等再包装过的错误不会被 slog 识别，可以用 Attr(err) 显式转换。
+*/
+
+// LogValue 实现 slog.LogValuer
+func (e *Error) LogValue() slog.Value {
+	return logValue(e)
+}
+
+// LogValue 实现 slog.LogValuer，每个错误一个分组，键为下标
+func (m *MultiError) LogValue() slog.Value {
+	errs := m.Errors()
+	attrs := make([]slog.Attr, len(errs))
+	for i, err := range errs {
+		attrs[i] = slog.Attr{Key: strconv.Itoa(i), Value: logValue(err)}
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// Attr 返回键为 "err" 的 slog.Attr，错误链上任意一层是 *Error 时都会带上元数据
+func Attr(err error) slog.Attr {
+	return slog.Attr{Key: "err", Value: logValue(err)}
+}
+
+func logValue(err error) slog.Value {
+	if err == nil {
+		return slog.StringValue("<nil>")
+	}
+	attrs := []slog.Attr{slog.String("msg", err.Error())}
+	if k := KindOf(err); k != KindUnknown {
+		attrs = append(attrs, slog.String("kind", k.String()))
+	}
+	if fields := FieldsOf(err); len(fields) > 0 {
+		keys := make([]string, 0, len(fields))
+		for k := range fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fa := make([]slog.Attr, len(keys))
+		for i, k := range keys {
+			fa[i] = slog.Any(k, fields[k])
+		}
+		attrs = append(attrs, slog.Attr{Key: "fields", Value: slog.GroupValue(fa...)})
+	}
+	if st := StackTraceOf(err); len(st) > 0 {
+		lines := make([]string, len(st))
+		for i, f := range st {
+			lines[i] = f.String()
+		}
+		attrs = append(attrs, slog.Any("stack", lines))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// StackTraceOf 返回错误链上最内层记录的调用栈，即错误最初产生的位置
+func StackTraceOf(err error) StackTrace {
+	var pcs []uintptr
+	for ; err != nil; err = errors.Unwrap(err) {
+		if e, ok := err.(*Error); ok && len(e.stack) > 0 {
+			pcs = e.stack
+		}
+	}
+	return frames(pcs)
+}
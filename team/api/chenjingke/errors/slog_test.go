@@ -0,0 +1,54 @@
+package errors
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogValue(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	err := With(E(KindNotFound, "no product"), "product_code", "P01")
+	logger.Info("load failed", "err", err)
+
+	var got struct {
+		Err struct {
+			Msg    string            `json:"msg"`
+			Kind   string            `json:"kind"`
+			Fields map[string]string `json:"fields"`
+			Stack  []string          `json:"stack"`
+		} `json:"err"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, buf.Bytes())
+	}
+	if got.Err.Msg != "no product" || got.Err.Kind != "not_found" || got.Err.Fields["product_code"] != "P01" {
+		t.Errorf("unexpected log record: %s", buf.Bytes())
+	}
+	if len(got.Err.Stack) == 0 || !strings.Contains(got.Err.Stack[0], "slog_test.go:") {
+		t.Errorf("stack = %v, want slog_test.go first", got.Err.Stack)
+	}
+}
+
+func TestAttrWrapped(t *testing.T) {
+	t.Cleanup(SetStackCapture(false))
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+	err := fmt.Errorf("outer: %w", With(E(KindConflict, "dup"), "id", 1))
+	logger.Error("save", Attr(err))
+	want := `level=ERROR msg=save err.msg="outer: dup" err.kind=conflict err.fields.id=1` + "\n"
+	if buf.String() != want {
+		t.Errorf("got  %q\nwant %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,78 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+)
+
+/*
+	查不到数据时各条数据访问路径的表现并不一致：
+		1.database/sql 的 QueryRow().Scan 返回 sql.ErrNoRows；
+		2.beego orm 的 QueryRow 返回 orm.ErrNoRows，即 errors.New("<QuerySeter> no row found")；
+		3.beego orm 的 QueryRows 查不到时返回 nil，需要调用方自己判断结果长度。
+	TranslateDB 把前两种以及唯一键/外键冲突、context 取消和超时统一映射到 Kind 上，
+	调用方只需要 errors.Is(err, ErrNotFound) 就能判断。
+*/
+
+// beego orm.ErrNoRows 的文本，这里不引入 orm 包，按文本匹配
+const ormNoRowsText = "<QuerySeter> no row found"
+
+// 常见驱动在违反约束时错误文本里带的片段（mysql、sqlite、postgres）
+var constraintTexts = []string{
+	"Error 1062",
+	"Error 1451",
+	"Error 1452",
+	"Duplicate entry",
+	"UNIQUE constraint failed",
+	"FOREIGN KEY constraint failed",
+	"violates unique constraint",
+	"violates foreign key constraint",
+}
+
+// TranslateDB 把数据访问层返回的错误映射到对应的 Kind 上，
+// 已经带类别或无法识别的错误原样返回，nil 返回 nil
+func TranslateDB(err error) error {
+	if err == nil || KindOf(err) != KindUnknown {
+		return err
+	}
+	k := dbKind(err)
+	if k == KindUnknown {
+		return err
+	}
+	return withKind(err, k, 2)
+}
+
+func dbKind(err error) Kind {
+	switch {
+	case errors.Is(err, sql.ErrNoRows), strings.Contains(err.Error(), ormNoRowsText):
+		return KindNotFound
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindDeadlineExceeded
+	case errors.Is(err, driver.ErrBadConn), errors.Is(err, sql.ErrConnDone):
+		return KindUnavailable
+	case isConstraintViolation(err):
+		return KindConflict
+	}
+	return KindUnknown
+}
+
+// isConstraintViolation 判断是否违反了数据库约束：
+// 优先看 SQLSTATE（23 类为完整性约束错误），否则按错误文本匹配
+func isConstraintViolation(err error) bool {
+	var st interface{ SQLState() string }
+	if errors.As(err, &st) {
+		return strings.HasPrefix(st.SQLState(), "23")
+	}
+	msg := err.Error()
+	for _, s := range constraintTexts {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
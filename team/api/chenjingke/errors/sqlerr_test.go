@@ -0,0 +1,89 @@
+package errors
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+)
+
+type pgError struct{ code string }
+
+func (e *pgError) Error() string    { return "pq: error " + e.code }
+func (e *pgError) SQLState() string { return e.code }
+
+func TestTranslateDB(t *testing.T) {
+	tests := []struct {
+		err  error
+		want error
+	}{
+		{sql.ErrNoRows, ErrNotFound},
+		{fmt.Errorf("query product: %w", sql.ErrNoRows), ErrNotFound},
+		{errors.New("<QuerySeter> no row found"), ErrNotFound},
+		{context.Canceled, ErrCanceled},
+		{fmt.Errorf("exec: %w", context.DeadlineExceeded), ErrDeadlineExceeded},
+		{sql.ErrConnDone, ErrUnavailable},
+		{errors.New("Error 1062: Duplicate entry 'a' for key 'PRIMARY'"), ErrConflict},
+		{errors.New("UNIQUE constraint failed: people.name"), ErrConflict},
+		{&pgError{"23505"}, ErrConflict},
+		{&pgError{"42P01"}, nil},
+		{errors.New("bad sql"), nil},
+	}
+	for _, tt := range tests {
+		got := TranslateDB(tt.err)
+		if !errors.Is(got, tt.err) {
+			t.Errorf("TranslateDB(%v) lost the original error", tt.err)
+		}
+		if tt.want == nil {
+			if got != tt.err {
+				t.Errorf("TranslateDB(%v) = %#v, want unchanged", tt.err, got)
+			}
+			continue
+		}
+		if !errors.Is(got, tt.want) {
+			t.Errorf("TranslateDB(%v): errors.Is(_, %v) = false", tt.err, tt.want)
+		}
+		if got.Error() != tt.err.Error() {
+			t.Errorf("TranslateDB(%v).Error() = %q", tt.err, got.Error())
+		}
+	}
+	if TranslateDB(nil) != nil {
+		t.Error("TranslateDB(nil) != nil")
+	}
+}
+
+func TestKindOf(t *testing.T) {
+	err := Wrap(E(KindPermission, "no access"), "open file")
+	if k := KindOf(err); k != KindPermission {
+		t.Errorf("KindOf = %v, want %v", k, KindPermission)
+	}
+	if !errors.Is(err, ErrPermission) || errors.Is(err, ErrNotFound) {
+		t.Error("errors.Is does not match by kind")
+	}
+	if k := KindOf(fmt.Errorf("x: %w", ErrNotFound)); k != KindNotFound {
+		t.Errorf("KindOf(sentinel) = %v, want %v", k, KindNotFound)
+	}
+	if k := KindOf(errors.New("plain")); k != KindUnknown {
+		t.Errorf("KindOf(plain) = %v, want %v", k, KindUnknown)
+	}
+	// errors.Join 和 MultiError 中的类别
+	joined := errors.Join(io.EOF, E(KindNotFound, "x"))
+	if k := KindOf(fmt.Errorf("load: %w", joined)); k != KindNotFound {
+		t.Errorf("KindOf(joined) = %v, want %v", k, KindNotFound)
+	}
+	if s := HTTPStatus(joined); s != http.StatusNotFound {
+		t.Errorf("HTTPStatus(joined) = %d, want %d", s, http.StatusNotFound)
+	}
+	var multi MultiError
+	multi.Append(io.EOF, WithKind(io.ErrUnexpectedEOF, KindInvalid))
+	if k := KindOf(Wrap(&multi, "batch")); k != KindInvalid {
+		t.Errorf("KindOf(multi) = %v, want %v", k, KindInvalid)
+	}
+	// 已经带类别的错误不会被覆盖
+	if k := KindOf(TranslateDB(WithKind(sql.ErrNoRows, KindInternal))); k != KindInternal {
+		t.Errorf("TranslateDB overwrote kind: %v", k)
+	}
+}
@@ -0,0 +1,67 @@
+package errors
+
+import (
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// 每个错误最多记录的调用帧数
+const maxStackDepth = 32
+
+// captureStack 控制创建/包装错误时是否记录调用栈，默认开启
+var captureStack atomic.Bool
+
+func init() {
+	captureStack.Store(true)
+}
+
+// SetStackCapture 打开或关闭调用栈的记录，返回恢复原设置的函数。
+// 测试中可以这样用：t.Cleanup(errors.SetStackCapture(false))
+func SetStackCapture(enabled bool) (restore func()) {
+	prev := captureStack.Swap(enabled)
+	return func() { captureStack.Store(prev) }
+}
+
+// Frame 是调用栈中的一帧
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// String 返回 file:line 形式
+func (f Frame) String() string {
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// StackTrace 是从最内层调用开始的调用帧列表
+type StackTrace []Frame
+
+// callers 记录调用者的程序计数器，skip 为需要跳过的 errors 包内部帧数
+func callers(skip int) []uintptr {
+	if !captureStack.Load() {
+		return nil
+	}
+	var pcs [maxStackDepth]uintptr
+	// +2 跳过 runtime.Callers 和 callers 自身
+	n := runtime.Callers(skip+2, pcs[:])
+	return pcs[:n:n]
+}
+
+// frames 把程序计数器解析成 StackTrace
+func frames(pcs []uintptr) StackTrace {
+	if len(pcs) == 0 {
+		return nil
+	}
+	st := make(StackTrace, 0, len(pcs))
+	fs := runtime.CallersFrames(pcs)
+	for {
+		f, more := fs.Next()
+		st = append(st, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+	return st
+}
@@ -0,0 +1,203 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+/*
+	把错误类别映射为 HTTP 状态码和 RPC 标准状态码（与 gRPC codes 数值一致），
+	handler 里不用再各自写 switch：
+		1.DefaultStatusTable 是默认映射，StatusTable.With 可以覆盖其中的项；
+		2.Lookup 先按 KindOf 查找，没有类别时识别 context 的取消/超时，其余一律按内部错误处理；
+		3.Handler 把错误渲染成 RFC 9457 的 application/problem+json 响应。
+*/
+
+// Code 是 RPC 标准状态码
+type Code uint32
+
+const (
+	CodeOK Code = iota
+	CodeCanceled
+	CodeUnknown
+	CodeInvalidArgument
+	CodeDeadlineExceeded
+	CodeNotFound
+	CodeAlreadyExists
+	CodePermissionDenied
+	CodeResourceExhausted
+	CodeFailedPrecondition
+	CodeAborted
+	CodeOutOfRange
+	CodeUnimplemented
+	CodeInternal
+	CodeUnavailable
+	CodeDataLoss
+	CodeUnauthenticated
+)
+
+var codeNames = [...]string{
+	CodeOK:                 "OK",
+	CodeCanceled:           "CANCELLED",
+	CodeUnknown:            "UNKNOWN",
+	CodeInvalidArgument:    "INVALID_ARGUMENT",
+	CodeDeadlineExceeded:   "DEADLINE_EXCEEDED",
+	CodeNotFound:           "NOT_FOUND",
+	CodeAlreadyExists:      "ALREADY_EXISTS",
+	CodePermissionDenied:   "PERMISSION_DENIED",
+	CodeResourceExhausted:  "RESOURCE_EXHAUSTED",
+	CodeFailedPrecondition: "FAILED_PRECONDITION",
+	CodeAborted:            "ABORTED",
+	CodeOutOfRange:         "OUT_OF_RANGE",
+	CodeUnimplemented:      "UNIMPLEMENTED",
+	CodeInternal:           "INTERNAL",
+	CodeUnavailable:        "UNAVAILABLE",
+	CodeDataLoss:           "DATA_LOSS",
+	CodeUnauthenticated:    "UNAUTHENTICATED",
+}
+
+func (c Code) String() string {
+	if int(c) < len(codeNames) {
+		return codeNames[c]
+	}
+	return "UNKNOWN"
+}
+
+// Status 是一个类别对应的 HTTP 状态码和 RPC 状态码
+type Status struct {
+	HTTP int
+	Code Code
+}
+
+// StatusClientClosed 是客户端主动断开时使用的状态码（nginx 的约定）
+const StatusClientClosed = 499
+
+// StatusTable 是类别到状态码的映射表
+type StatusTable map[Kind]Status
+
+// DefaultStatusTable 是默认的映射表
+var DefaultStatusTable = StatusTable{
+	KindUnknown:          {http.StatusInternalServerError, CodeUnknown},
+	KindInvalid:          {http.StatusBadRequest, CodeInvalidArgument},
+	KindNotFound:         {http.StatusNotFound, CodeNotFound},
+	KindConflict:         {http.StatusConflict, CodeAlreadyExists},
+	KindPermission:       {http.StatusForbidden, CodePermissionDenied},
+	KindUnauthenticated:  {http.StatusUnauthorized, CodeUnauthenticated},
+	KindCanceled:         {StatusClientClosed, CodeCanceled},
+	KindDeadlineExceeded: {http.StatusGatewayTimeout, CodeDeadlineExceeded},
+	KindUnavailable:      {http.StatusServiceUnavailable, CodeUnavailable},
+	KindInternal:         {http.StatusInternalServerError, CodeInternal},
+}
+
+// With 返回覆盖了 kind 对应项的新表，原表不变
+func (t StatusTable) With(kind Kind, s Status) StatusTable {
+	c := make(StatusTable, len(t)+1)
+	for k, v := range t {
+		c[k] = v
+	}
+	c[kind] = s
+	return c
+}
+
+// Lookup 返回 err 对应的状态，表里没有的类别回落到 DefaultStatusTable；
+// MultiError 取其中 HTTP 状态码最大的一项
+func (t StatusTable) Lookup(err error) Status {
+	if err == nil {
+		return Status{http.StatusOK, CodeOK}
+	}
+	if m, ok := err.(*MultiError); ok {
+		var worst Status
+		for _, e := range m.Errors() {
+			if s := t.Lookup(e); s.HTTP > worst.HTTP {
+				worst = s
+			}
+		}
+		if worst.HTTP != 0 {
+			return worst
+		}
+	}
+	k := statusKind(err)
+	if s, ok := t[k]; ok {
+		return s
+	}
+	if s, ok := DefaultStatusTable[k]; ok {
+		return s
+	}
+	return Status{http.StatusInternalServerError, CodeUnknown}
+}
+
+func statusKind(err error) Kind {
+	k := KindOf(err)
+	switch {
+	case k != KindUnknown:
+		return k
+	case errors.Is(err, context.Canceled):
+		return KindCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return KindDeadlineExceeded
+	}
+	return KindUnknown
+}
+
+// HTTPStatus 按默认表返回 err 对应的 HTTP 状态码，nil 为 200
+func HTTPStatus(err error) int {
+	return DefaultStatusTable.Lookup(err).HTTP
+}
+
+// RPCCode 按默认表返回 err 对应的 RPC 状态码，nil 为 CodeOK
+func RPCCode(err error) Code {
+	return DefaultStatusTable.Lookup(err).Code
+}
+
+// Problem 是 RFC 9457 定义的问题详情，kind、code、fields、errors 为扩展成员
+type Problem struct {
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title"`
+	Status   int                    `json:"status"`
+	Detail   string                 `json:"detail,omitempty"`
+	Instance string                 `json:"instance,omitempty"`
+	Kind     string                 `json:"kind,omitempty"`
+	Code     string                 `json:"code,omitempty"`
+	Fields   map[string]interface{} `json:"fields,omitempty"`
+	Errors   []ErrorDetail          `json:"errors,omitempty"`
+}
+
+// Handler 按默认表把 err 渲染成 problem+json 响应
+func Handler(err error) http.Handler {
+	return DefaultStatusTable.Handler(err)
+}
+
+// Handler 把 err 渲染成 problem+json 响应。
+// 5xx 错误不输出 detail 和 fields，避免把内部信息暴露给调用方
+func (t StatusTable) Handler(err error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s := t.Lookup(err)
+		p := Problem{
+			Type:     "about:blank",
+			Title:    http.StatusText(s.HTTP),
+			Status:   s.HTTP,
+			Instance: r.URL.Path,
+			Code:     s.Code.String(),
+		}
+		if p.Title == "" {
+			p.Title = s.Code.String()
+		}
+		if k := statusKind(err); k != KindUnknown {
+			p.Kind = k.String()
+		}
+		if err != nil && s.HTTP < http.StatusInternalServerError {
+			p.Detail = err.Error()
+			p.Fields = FieldsOf(err)
+			if m, ok := err.(*MultiError); ok {
+				for _, e := range m.Errors() {
+					p.Errors = append(p.Errors, toJSON(e))
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(s.HTTP)
+		json.NewEncoder(w).Encode(p)
+	})
+}
@@ -0,0 +1,86 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestStatusLookup(t *testing.T) {
+	var multi MultiError
+	multi.Append(E(KindInvalid, "bad name"), E(KindNotFound, "no product"))
+
+	tests := []struct {
+		err  error
+		http int
+		code Code
+	}{
+		{nil, http.StatusOK, CodeOK},
+		{E(KindNotFound, "x"), http.StatusNotFound, CodeNotFound},
+		{fmt.Errorf("wrap: %w", ErrPermission), http.StatusForbidden, CodePermissionDenied},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout, CodeDeadlineExceeded},
+		{context.Canceled, StatusClientClosed, CodeCanceled},
+		{fmt.Errorf("plain"), http.StatusInternalServerError, CodeUnknown},
+		{&multi, http.StatusNotFound, CodeNotFound},
+	}
+	for _, tt := range tests {
+		if got := HTTPStatus(tt.err); got != tt.http {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tt.err, got, tt.http)
+		}
+		if got := RPCCode(tt.err); got != tt.code {
+			t.Errorf("RPCCode(%v) = %v, want %v", tt.err, got, tt.code)
+		}
+	}
+}
+
+func TestStatusTableWith(t *testing.T) {
+	tbl := StatusTable{}.With(KindConflict, Status{http.StatusUnprocessableEntity, CodeFailedPrecondition})
+	if got := tbl.Lookup(ErrConflict); got.HTTP != http.StatusUnprocessableEntity || got.Code != CodeFailedPrecondition {
+		t.Errorf("override Lookup = %+v", got)
+	}
+	if got := tbl.Lookup(ErrNotFound); got.HTTP != http.StatusNotFound {
+		t.Errorf("fallback Lookup = %+v", got)
+	}
+	if got := DefaultStatusTable.Lookup(ErrConflict); got.HTTP != http.StatusConflict {
+		t.Errorf("DefaultStatusTable modified: %+v", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		err  error
+		want Problem
+	}{
+		{
+			With(E(KindNotFound, "no product"), "product_code", "P01"),
+			Problem{Type: "about:blank", Title: "Not Found", Status: 404, Detail: "no product",
+				Instance: "/products/P01", Kind: "not_found", Code: "NOT_FOUND",
+				Fields: map[string]interface{}{"product_code": "P01"}},
+		},
+		{
+			With(New("db down"), "dsn", "secret"),
+			Problem{Type: "about:blank", Title: "Internal Server Error", Status: 500,
+				Instance: "/products/P01", Code: "UNKNOWN"},
+		},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		Handler(tt.err).ServeHTTP(rec, httptest.NewRequest("GET", "/products/P01", nil))
+		if ct := rec.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		if rec.Code != tt.want.Status {
+			t.Errorf("status = %d, want %d", rec.Code, tt.want.Status)
+		}
+		var got Problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("body = %+v\nwant   %+v", got, tt.want)
+		}
+	}
+}
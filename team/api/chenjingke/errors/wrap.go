@@ -0,0 +1,134 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+/*
+	errors.New 和 fmt.Errorf 生成的错误只有一段文本，打到日志里看不出是在哪里产生的。
+	Error 在创建或包装时记录调用栈：
+		1.New/Errorf 创建新的错误，Wrap/Wrapf 给已有错误加一层说明；
+		2.StackTrace() 返回该层记录的调用帧；
+		3.用 %+v 打印时会输出整条错误链，每一层都带上 file:line；
+		4.实现了 Unwrap，所以标准库的 errors.Is/errors.As 依然可用。
+*/
+
+// Error 是带调用栈的错误
+type Error struct {
+	msg    string
+	cause  error
+	inline bool // msg 已经包含 cause 的文本（Errorf 使用 %w 时）
+	kind   Kind
+	fields map[string]interface{}
+	stack  []uintptr
+}
+
+// New 返回一个记录了调用位置的错误
+func New(text string) error {
+	return &Error{msg: text, stack: callers(1)}
+}
+
+// Errorf 同 fmt.Errorf，支持 %w，并记录调用位置
+func Errorf(format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+	e := &Error{msg: err.Error(), stack: callers(1)}
+	switch u := err.(type) {
+	case interface{ Unwrap() error }:
+		e.cause, e.inline = u.Unwrap(), true
+	case interface{ Unwrap() []error }:
+		e.cause, e.inline = errors.Join(u.Unwrap()...), true
+	}
+	return e
+}
+
+// Wrap 给 err 加一层说明并记录调用位置，err 为 nil 时返回 nil
+func Wrap(err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{msg: msg, cause: err, stack: callers(1)}
+}
+
+// Wrapf 同 Wrap，说明文字按 format 格式化
+func Wrapf(err error, format string, a ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{msg: fmt.Sprintf(format, a...), cause: err, stack: callers(1)}
+}
+
+func (e *Error) Error() string {
+	if e.cause == nil || e.inline {
+		return e.msg
+	}
+	return e.msg + ": " + e.cause.Error()
+}
+
+// Unwrap 返回被包装的错误
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// StackTrace 返回创建或包装该错误时的调用栈，关闭记录时为空
+func (e *Error) StackTrace() StackTrace {
+	return frames(e.stack)
+}
+
+// Format 实现 fmt.Formatter：
+//
+//	%s, %v  同 Error()
+//	%q      带引号的 Error()
+//	%+v     整条错误链，每层附带调用栈
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		if s.Flag('+') {
+			writeChain(s, e)
+			return
+		}
+		io.WriteString(s, e.Error())
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	}
+}
+
+// writeChain 从外到内逐层输出错误信息和调用栈
+func writeChain(w io.Writer, err error) {
+	for i := 0; err != nil; i++ {
+		if i > 0 {
+			io.WriteString(w, "\ncaused by: ")
+		}
+		e, ok := err.(*Error)
+		if !ok {
+			io.WriteString(w, err.Error())
+			err = errors.Unwrap(err)
+			continue
+		}
+		io.WriteString(w, e.msg)
+		if e.kind != KindUnknown {
+			fmt.Fprintf(w, " [%s]", e.kind)
+		}
+		for _, f := range e.StackTrace() {
+			fmt.Fprintf(w, "\n\t%s\n\t\t%s:%d", f.Function, f.File, f.Line)
+		}
+		err = e.cause
+		if e.inline {
+			err = nextError(err)
+		}
+	}
+}
+
+// nextError 跳过 msg 中已经包含了文本的普通错误，返回链上下一个 *Error，没有时返回 nil
+func nextError(err error) error {
+	for err != nil {
+		if _, ok := err.(*Error); ok {
+			return err
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewStackTrace(t *testing.T) {
+	err := New("标的类型有误")
+	st := err.(*Error).StackTrace()
+	if len(st) == 0 {
+		t.Fatal("StackTrace is empty")
+	}
+	if !strings.HasSuffix(st[0].Function, "TestNewStackTrace") {
+		t.Errorf("top frame = %s, want TestNewStackTrace", st[0].Function)
+	}
+	if !strings.HasSuffix(st[0].File, "wrap_test.go") {
+		t.Errorf("top file = %s, want wrap_test.go", st[0].File)
+	}
+}
+
+func TestWrapChain(t *testing.T) {
+	const name, id = "bimmler", 17
+	err := Wrap(Errorf("user %q (id %d) not found: %w", name, id, io.EOF), "load user")
+	if got, want := err.Error(), `load user: user "bimmler" (id 17) not found: EOF`; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.Is(err, io.EOF) {
+		t.Error("errors.Is(err, io.EOF) = false")
+	}
+	if got := fmt.Sprintf("%v", err); got != err.Error() {
+		t.Errorf("%%v = %q, want %q", got, err.Error())
+	}
+
+	full := fmt.Sprintf("%+v", err)
+	for _, want := range []string{"load user\n", "\ncaused by: user \"bimmler\"", "wrap_test.go:"} {
+		if !strings.Contains(full, want) {
+			t.Errorf("%%+v missing %q:\n%s", want, full)
+		}
+	}
+	// %w 的 EOF 已经在上一层的文本中，不再单独输出
+	if strings.Contains(full, "caused by: EOF") {
+		t.Errorf("%%+v repeats inline cause:\n%s", full)
+	}
+	if n := strings.Count(full, "TestWrapChain"); n != 2 {
+		t.Errorf("%%+v has %d TestWrapChain frames, want 2:\n%s", n, full)
+	}
+}
+
+func TestFormatInlineCause(t *testing.T) {
+	t.Cleanup(SetStackCapture(false))
+	if got, want := fmt.Sprintf("%+v", Errorf("load: %w", io.EOF)), "load: EOF"; got != want {
+		t.Errorf("%%+v = %q, want %q", got, want)
+	}
+	// 被 %w 包装的 *Error 仍然输出，以保留其调用栈
+	err := Errorf("load: %w", fmt.Errorf("read: %w", New("a")))
+	if got, want := fmt.Sprintf("%+v", err), "load: read: a\ncaused by: a"; got != want {
+		t.Errorf("%%+v = %q, want %q", got, want)
+	}
+}
+
+func TestWrapNil(t *testing.T) {
+	if err := Wrap(nil, "x"); err != nil {
+		t.Errorf("Wrap(nil) = %v, want nil", err)
+	}
+	if err := Wrapf(nil, "x %d", 1); err != nil {
+		t.Errorf("Wrapf(nil) = %v, want nil", err)
+	}
+}
+
+func TestSetStackCapture(t *testing.T) {
+	t.Cleanup(SetStackCapture(false))
+	err := Wrap(New("a"), "b")
+	if st := err.(*Error).StackTrace(); st != nil {
+		t.Errorf("StackTrace = %v, want nil", st)
+	}
+	if got, want := fmt.Sprintf("%+v", err), "b\ncaused by: a"; got != want {
+		t.Errorf("%%+v = %q, want %q", got, want)
+	}
+}
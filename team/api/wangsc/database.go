@@ -1,47 +1,60 @@
 /**
  * 这是 Go 提供的操作 SQL/SQL-Like 数据库的通用接口，但 Go 标准库并没有提供具体数据库的实现，需要结合第三方的驱动来使用该接口
  * 该包有一个子包：driver，它定义了一些接口供数据库驱动实现，一般业务代码中使用 database/sql 包即可，尽量避免使用 driver 这个子包
+ * 下面的例子使用内存中的驱动 memdb，语句写法见 memdb 目录；这些用法对应的测试见 memdb/memdb_test.go
  */
 
 package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
+
+	_ "github.com/ZcmOrg/demo-go-base/team/api/wangsc/memdb"
 )
 
-func testOpenDB() (*sql.DB, err) {
-	db, _ := sql.Open("mysql", "root:@tcp(localhost:3306)/test?charset=utf8") //一个数据库实例
-	db.Driver()                                                               //Driver方法返回数据库下层驱动
-	db.Ping()                                                                 //Ping检查与数据库的连接是否仍有效，如果需要会创建连接
-	db.SetMaxIdleConns()                                                      //连接池最大空闲连接数
-	db.SetMaxOpenConns()                                                      //连接池最多连接数
-	db.Exec("", "")
+func openDB() (*sql.DB, error) {
+	db, err := sql.Open("memdb", "test") //一个数据库实例，memdb 是内存中的驱动，同名的 DSN 共用一个数据库
+	if err != nil {
+		return nil, err
+	}
+	db.Driver()           //Driver方法返回数据库下层驱动
+	db.SetMaxIdleConns(2) //连接池最大空闲连接数
+	db.SetMaxOpenConns(4) //连接池最多连接数
+	for _, q := range []string{
+		"CREATE|people|name=string,age=int32,bdate=nulldatetime",
+		"INSERT|people|name=Alice,age=1",
+		"INSERT|people|name=Bob,age=2",
+		"INSERT|people|name=Chris,age=3,bdate=2000-01-02T03:04:05Z",
+	} {
+		if _, err := db.Exec(q); err != nil { //Exec执行一次命令（包括查询、删除、更新、插入等），不返回任何执行结果。参数 args 表示 query 中的占位参数
+			db.Close()
+			return nil, err
+		}
+	}
 	return db, nil
 }
 
-func testCloseDB(db *sql.DB) {
+func closeDB(db *sql.DB) {
 	db.Close() //Close关闭数据库，释放任何打开的资源。一般不会关闭 DB，因为 DB 句柄通常被多个 go 程共享，并长期活跃
 }
 
-func testPing() {
-	db, _ := openDB()
-	defer closeDB(db)
-	if err := db.Ping(); err != nil {
-		t.Errorf("err was %#v, expected nil", err)
-		return
-	}
+func testPing(db *sql.DB) error {
+	return db.Ping() //Ping检查与数据库的连接是否仍有效，如果需要会创建连接
 }
 
-func testQuery() {
-	db, _ := openDB()
-	defer closeDB(db)
-	rows, err := db.Query("select * from table") //Query执行一次查询，返回多行结果（即Rows），一般用于执行 select 命令。参数 args 表示query中的占位参数
+func testQuery(db *sql.DB) error {
+	rows, err := db.Query("SELECT|people|age,name|") //Query执行一次查询，返回多行结果（即Rows），一般用于执行 select 命令。参数 args 表示query中的占位参数
 	if err != nil {
-		t.Fatalf("Query: %v", err)
+		return err
 	}
+	defer rows.Close()           //Close关闭Rows
 	cName, err := rows.Columns() //Columns返回列名
+	if err != nil {
+		return err
+	}
 	fmt.Println(cName)
 	type row struct {
 		age  int
@@ -50,70 +63,94 @@ func testQuery() {
 	got := []row{}
 	for rows.Next() { //Next准备用于Scan方法的下一行结果
 		var r row
-		err = rows.Scan(&r.age, &r.name)
-		if err != nil {
-			t.Fatalf("Scan: %v", err)
+		if err := rows.Scan(&r.age, &r.name); err != nil {
+			return err
 		}
 		got = append(got, r)
 	}
-	rows.Close()     //Close关闭Rows
-	err = rows.Err() //Err返回可能的、在迭代时出现的错误
-	if err != nil {
-		t.Fatalf("Err: %v", err)
-	}
+	fmt.Println(got)
+	return rows.Err() //Err返回可能的、在迭代时出现的错误
 }
 
-func testQueryRow() {
-	db, _ := openDB()
-	defer closeDB(db)
-	var name string
+func testQueryRow(db *sql.DB) error {
 	var age int
 	var birthday time.Time
 
 	//QueryRow执行一次查询，并期望返回最多一行结果（即Row）
-	db.QueryRow("select age from users where uid = ?", 3).Scan(&age) //Scan将该行查询结果各列分别保存进dest参数指定的值中
-	db.QueryRow("select age from users where uid = ?", 3).Scan(&birthday)
+	if err := db.QueryRow("SELECT|people|age|name=?", "Chris").Scan(&age); err != nil { //Scan将该行查询结果各列分别保存进dest参数指定的值中
+		return err
+	}
+	if err := db.QueryRow("SELECT|people|bdate|age=?", 3).Scan(&birthday); err != nil {
+		return err
+	}
+	err := db.QueryRow("SELECT|people|age|name=?", "nobody").Scan(&age)
+	if !errors.Is(err, sql.ErrNoRows) { //没有结果时 Scan 返回 sql.ErrNoRows
+		return fmt.Errorf("query of missing row: got %v, want sql.ErrNoRows", err)
+	}
+	return nil
 }
 
-func testStatement() {
-	db, _ := openDB()
-	defer closeDB(db)
+func testStatement(db *sql.DB) error {
 	stmt, err := db.Prepare("SELECT|people|age|name=?") //Prepare创建一个准备好的状态用于之后的查询和命令
 	if err != nil {
-		t.Fatalf("Prepare: %v", err)
+		return err
 	}
-	err = stmt.Close() //Close关闭状态
-	if err != nil {
-		t.Fatalf("Close: %v", err)
+	var age int
+	if err := stmt.QueryRow("Bob").Scan(&age); err != nil { //QueryRow使用提供的参数执行准备好的查询状态
+		return err
+	}
+	if err := stmt.Close(); err != nil { //Close关闭状态，之后再查询会返回错误
+		return err
 	}
 	var name string
-	err = stmt.QueryRow("foo").Scan(&name) //QueryRow使用提供的参数执行准备好的查询状态
-	if err == nil {
-		t.Errorf("expected error from QueryRow.Scan after Stmt.Close")
+	if err := stmt.QueryRow("foo").Scan(&name); err == nil {
+		return errors.New("expected error from QueryRow.Scan after Stmt.Close")
 	}
+	return nil
 }
 
-func testExec() {
-	db, _ := openDB()
-	defer closeDB(db)
-	exec(t, db, "CREATE|t1|name=string,age=int32,dead=bool") //Exec执行一次命令（包括查询、删除、更新、插入等），不返回任何执行结果。参数 args 表示 query 中的占位参数
+func testExec(db *sql.DB) error {
+	if _, err := db.Exec("CREATE|t1|name=string,age=int32,dead=bool"); err != nil {
+		return err
+	}
 	stmt, err := db.Prepare("INSERT|t1|name=?,age=?")
 	if err != nil {
-		t.Errorf("Stmt, err = %v, %v", stmt, err)
+		return err
 	}
 	defer stmt.Close()
+	res, err := stmt.Exec("Brad", 31) //Exec使用提供的参数执行准备好的命令
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected() //RowsAffected返回受影响的行数
+	fmt.Println(n)
+	return err
 }
 
-func testTx() {
-	db, _ := openDB()
-	defer closeDB(db)
-	tx, _ := db.Begin() //Begin开始一个事务
+func testTx(db *sql.DB) error {
+	tx, err := db.Begin() //Begin开始一个事务
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //Rollback放弃并回滚事务，已经递交的事务返回 sql.ErrTxDone
 	name := "test"
-	age := 2
-	tx.Exec("update users set name = ? where age = ?", name, age) //Exec执行命令，但不返回结果。例如执行insert和update
-	rows, _ := tx.Query()                                         //Query执行查询并返回零到多行结果（Rows）
-	row := tx.QueryRow()                                          //QueryRow执行查询并期望返回最多一行结果（Row）
-	stmt, _ := tx.Prepare()                                       //Prepare准备一个专用于该事务的状态
-	tx.Commit()                                                   //Commit递交事务
-	tx.Rollback()                                                 //Rollback放弃并回滚事务
+	age := 4
+	if _, err := tx.Exec("INSERT|people|name=?,age=?", name, age); err != nil { //Exec执行命令，但不返回结果。例如执行insert
+		return err
+	}
+	rows, err := tx.Query("SELECT|people|name|") //Query执行查询并返回零到多行结果（Rows）
+	if err != nil {
+		return err
+	}
+	rows.Close()
+	var got int
+	if err := tx.QueryRow("SELECT|people|age|name=?", name).Scan(&got); err != nil { //QueryRow执行查询并期望返回最多一行结果（Row），事务内能看到自己的写入
+		return err
+	}
+	stmt, err := tx.Prepare("SELECT|people|age|name=?") //Prepare准备一个专用于该事务的状态
+	if err != nil {
+		return err
+	}
+	stmt.Close()
+	return tx.Commit() //Commit递交事务
 }
@@ -0,0 +1,519 @@
+/**
+ * memdb 是一个内存中的 database/sql 驱动，支持 database.go 里 CREATE|t|... 这种用 | 分隔的语句，
+ * 不需要 MySQL 就能运行数据库的例子和测试：
+ *   db, err := sql.Open("memdb", "test")
+ * 数据源名字相同的 sql.DB 共享同一份数据，进程退出后数据消失
+ */
+
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+func init() {
+	sql.Register("memdb", &Driver{})
+}
+
+// Driver 实现 driver.Driver，已经以 "memdb" 注册
+type Driver struct{}
+
+var (
+	dbsMu sync.Mutex
+	dbs   = make(map[string]*memDB)
+)
+
+// Open 打开名为 name 的内存数据库，不存在时创建
+func (d *Driver) Open(name string) (driver.Conn, error) {
+	dbsMu.Lock()
+	defer dbsMu.Unlock()
+	db, ok := dbs[name]
+	if !ok {
+		db = &memDB{tables: make(map[string]*table)}
+		dbs[name] = db
+	}
+	return &conn{db: db}, nil
+}
+
+// Drop 删除名为 name 的内存数据库，已经打开的连接仍然使用旧的数据
+func Drop(name string) {
+	dbsMu.Lock()
+	delete(dbs, name)
+	dbsMu.Unlock()
+}
+
+type memDB struct {
+	mu     sync.RWMutex
+	tables map[string]*table
+}
+
+type table struct {
+	cols []column
+	rows [][]driver.Value
+}
+
+type column struct {
+	name     string
+	typ      string
+	nullable bool
+}
+
+func (t *table) colIndex(name string) int {
+	return slices.IndexFunc(t.cols, func(c column) bool { return c.name == name })
+}
+
+func validType(typ string) bool {
+	switch typ {
+	case "string", "int32", "int64", "int", "float64", "bool", "datetime", "blob":
+		return true
+	}
+	return false
+}
+
+// convert 把参数或字面值转换为列类型对应的 driver.Value
+func (c column) convert(v driver.Value) (driver.Value, error) {
+	if v == nil {
+		if !c.nullable {
+			return nil, fmt.Errorf("memdb: column %q is not nullable", c.name)
+		}
+		return nil, nil
+	}
+	bad := func() (driver.Value, error) {
+		return nil, fmt.Errorf("memdb: cannot convert %T(%v) to %s for column %q", v, v, c.typ, c.name)
+	}
+	switch c.typ {
+	case "string":
+		switch x := v.(type) {
+		case string:
+			return x, nil
+		case []byte:
+			return string(x), nil
+		}
+	case "blob":
+		switch x := v.(type) {
+		case []byte:
+			return slices.Clone(x), nil
+		case string:
+			return []byte(x), nil
+		}
+	case "int32", "int64", "int":
+		var n int64
+		switch x := v.(type) {
+		case int64:
+			n = x
+		case string:
+			var err error
+			if n, err = strconv.ParseInt(x, 10, 64); err != nil {
+				return bad()
+			}
+		default:
+			return bad()
+		}
+		if c.typ == "int32" && int64(int32(n)) != n {
+			return nil, fmt.Errorf("memdb: %d overflows int32 column %q", n, c.name)
+		}
+		return n, nil
+	case "float64":
+		switch x := v.(type) {
+		case float64:
+			return x, nil
+		case int64:
+			return float64(x), nil
+		case string:
+			if f, err := strconv.ParseFloat(x, 64); err == nil {
+				return f, nil
+			}
+		}
+	case "bool":
+		switch x := v.(type) {
+		case bool:
+			return x, nil
+		case int64:
+			if x == 0 || x == 1 {
+				return x == 1, nil
+			}
+		case string:
+			if b, err := strconv.ParseBool(x); err == nil {
+				return b, nil
+			}
+		}
+	case "datetime":
+		switch x := v.(type) {
+		case time.Time:
+			return x, nil
+		case string:
+			if t, err := time.Parse(time.RFC3339Nano, x); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return bad()
+}
+
+// zero 是 INSERT 没有给出的非空列的值
+func (c column) zero() driver.Value {
+	if c.nullable {
+		return nil
+	}
+	switch c.typ {
+	case "string":
+		return ""
+	case "blob":
+		return []byte{}
+	case "float64":
+		return 0.0
+	case "bool":
+		return false
+	case "datetime":
+		return time.Time{}
+	}
+	return int64(0)
+}
+
+// conn 是一个连接，同一时间最多有一个事务
+type conn struct {
+	db     *memDB
+	tx     *tx
+	closed bool
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if c.closed {
+		return nil, driver.ErrBadConn
+	}
+	cmd, err := parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{c: c, cmd: cmd}, nil
+}
+
+func (c *conn) Close() error {
+	c.tx = nil
+	c.closed = true
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if c.tx != nil {
+		return nil, errors.New("memdb: transaction already in progress")
+	}
+	c.tx = &tx{c: c, readOnly: opts.ReadOnly, creates: make(map[string]*table), inserts: make(map[string][][]driver.Value)}
+	return c.tx, nil
+}
+
+// tx 在提交前把建表和插入记在自己这里，同一连接上的查询能看到，提交时一次性写入
+type tx struct {
+	c        *conn
+	readOnly bool
+	creates  map[string]*table
+	order    []string // 建表顺序
+	inserts  map[string][][]driver.Value
+}
+
+func (t *tx) Commit() error {
+	if t.c.tx != t {
+		return sql.ErrTxDone
+	}
+	t.c.tx = nil
+	db := t.c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	for _, name := range t.order {
+		if _, ok := db.tables[name]; ok {
+			return fmt.Errorf("memdb: table %q already exists", name)
+		}
+	}
+	for _, name := range t.order {
+		db.tables[name] = t.creates[name]
+	}
+	for name, rows := range t.inserts {
+		tb := db.tables[name]
+		tb.rows = append(tb.rows, rows...)
+	}
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	if t.c.tx != t {
+		return sql.ErrTxDone
+	}
+	t.c.tx = nil
+	return nil
+}
+
+type stmt struct {
+	c      *conn
+	cmd    *command
+	closed bool
+}
+
+func (s *stmt) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return s.cmd.nargs
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), named(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), named(args))
+}
+
+func named(args []driver.Value) []driver.NamedValue {
+	out := make([]driver.NamedValue, len(args))
+	for i, v := range args {
+		out[i] = driver.NamedValue{Ordinal: i + 1, Value: v}
+	}
+	return out
+}
+
+func (s *stmt) check(ctx context.Context) error {
+	if s.closed {
+		return errors.New("memdb: statement is closed")
+	}
+	if s.c.closed {
+		return driver.ErrBadConn
+	}
+	return ctx.Err()
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	if err := s.check(ctx); err != nil {
+		return nil, err
+	}
+	if t := s.c.tx; t != nil && t.readOnly {
+		return nil, errors.New("memdb: write in read-only transaction")
+	}
+	switch s.cmd.verb {
+	case "CREATE":
+		return s.create()
+	case "INSERT":
+		return s.insert(args)
+	}
+	return nil, fmt.Errorf("memdb: %s does not support Exec, use Query", s.cmd.verb)
+}
+
+func (s *stmt) create() (driver.Result, error) {
+	name := s.cmd.table
+	tb := &table{cols: s.cmd.cols}
+	db := s.c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	_, committed := db.tables[name]
+	if t := s.c.tx; t != nil {
+		if _, ok := t.creates[name]; ok || committed {
+			return nil, fmt.Errorf("memdb: table %q already exists", name)
+		}
+		t.creates[name] = tb
+		t.order = append(t.order, name)
+		return driver.ResultNoRows, nil
+	}
+	if committed {
+		return nil, fmt.Errorf("memdb: table %q already exists", name)
+	}
+	db.tables[name] = tb
+	return driver.ResultNoRows, nil
+}
+
+// lookup 查找表，事务中先找本事务创建的表；调用方持有 db.mu
+func (s *stmt) lookup() (*table, error) {
+	if t := s.c.tx; t != nil {
+		if tb, ok := t.creates[s.cmd.table]; ok {
+			return tb, nil
+		}
+	}
+	if tb, ok := s.c.db.tables[s.cmd.table]; ok {
+		return tb, nil
+	}
+	return nil, fmt.Errorf("memdb: table %q does not exist", s.cmd.table)
+}
+
+// value 返回赋值或条件的值：? 取参数，否则为字面值
+func value(a assign, args []driver.NamedValue) driver.Value {
+	if a.arg >= 0 {
+		return args[a.arg].Value
+	}
+	if a.lit == "NULL" {
+		return nil
+	}
+	return a.lit
+}
+
+func (s *stmt) insert(args []driver.NamedValue) (driver.Result, error) {
+	db := s.c.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	tb, err := s.lookup()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]driver.Value, len(tb.cols))
+	set := make([]bool, len(tb.cols))
+	for _, a := range s.cmd.sets {
+		i := tb.colIndex(a.col)
+		if i < 0 {
+			return nil, fmt.Errorf("memdb: table %q has no column %q", s.cmd.table, a.col)
+		}
+		v, err := tb.cols[i].convert(value(a, args))
+		if err != nil {
+			return nil, err
+		}
+		row[i], set[i] = v, true
+	}
+	for i, c := range tb.cols {
+		if !set[i] {
+			row[i] = c.zero()
+		}
+	}
+	if t := s.c.tx; t != nil {
+		t.inserts[s.cmd.table] = append(t.inserts[s.cmd.table], row)
+	} else {
+		tb.rows = append(tb.rows, row)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	if err := s.check(ctx); err != nil {
+		return nil, err
+	}
+	if s.cmd.verb != "SELECT" {
+		return nil, fmt.Errorf("memdb: %s does not support Query, use Exec", s.cmd.verb)
+	}
+	db := s.c.db
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	tb, err := s.lookup()
+	if err != nil {
+		return nil, err
+	}
+
+	var sel []int
+	for _, name := range s.cmd.sel {
+		if name == "*" {
+			for i := range tb.cols {
+				sel = append(sel, i)
+			}
+			continue
+		}
+		i := tb.colIndex(name)
+		if i < 0 {
+			return nil, fmt.Errorf("memdb: table %q has no column %q", s.cmd.table, name)
+		}
+		sel = append(sel, i)
+	}
+	type cond struct {
+		i int
+		v driver.Value
+	}
+	var conds []cond
+	for _, a := range s.cmd.where {
+		i := tb.colIndex(a.col)
+		if i < 0 {
+			return nil, fmt.Errorf("memdb: table %q has no column %q", s.cmd.table, a.col)
+		}
+		v, err := tb.cols[i].convert(value(a, args))
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond{i, v})
+	}
+
+	all := tb.rows
+	if t := s.c.tx; t != nil {
+		all = append(slices.Clip(all), t.inserts[s.cmd.table]...)
+	}
+	r := &rows{tb: tb, sel: sel}
+scan:
+	for _, row := range all {
+		for _, c := range conds {
+			if !equal(row[c.i], c.v) {
+				continue scan
+			}
+		}
+		out := make([]driver.Value, len(sel))
+		for j, i := range sel {
+			out[j] = row[i]
+			if b, ok := out[j].([]byte); ok {
+				out[j] = slices.Clone(b)
+			}
+		}
+		r.rows = append(r.rows, out)
+	}
+	return r, nil
+}
+
+func equal(a, b driver.Value) bool {
+	switch x := a.(type) {
+	case []byte:
+		y, ok := b.([]byte)
+		return ok && string(x) == string(y)
+	case time.Time:
+		y, ok := b.(time.Time)
+		return ok && x.Equal(y)
+	}
+	return a == b
+}
+
+// rows 是查询时复制出来的结果，之后的写入不会影响它
+type rows struct {
+	tb   *table
+	sel  []int
+	rows [][]driver.Value
+	pos  int
+}
+
+func (r *rows) Columns() []string {
+	names := make([]string, len(r.sel))
+	for j, i := range r.sel {
+		names[j] = r.tb.cols[i].name
+	}
+	return names
+}
+
+func (r *rows) Close() error {
+	r.pos = len(r.rows)
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if r.pos >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.pos])
+	r.pos++
+	return nil
+}
+
+// ColumnTypeDatabaseTypeName 返回建表时的类型名，不含 null 前缀
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.tb.cols[r.sel[index]].typ
+}
+
+// ColumnTypeNullable 返回列是否可以为 NULL
+func (r *rows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	return r.tb.cols[r.sel[index]].nullable, true
+}
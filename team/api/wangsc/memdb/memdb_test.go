@@ -0,0 +1,285 @@
+package memdb
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// openDB 打开以测试名命名的数据库，并建好 people 表
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	name := t.Name()
+	db, err := sql.Open("memdb", name)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		Drop(name)
+	})
+	exec(t, db, "CREATE|people|name=string,age=int32,photo=nullblob,bdate=nulldatetime")
+	exec(t, db, "INSERT|people|name=Alice,age=?,photo=APHOTO", 1)
+	exec(t, db, "INSERT|people|name=Bob,age=?,photo=?", 2, []byte("BPHOTO"))
+	exec(t, db, "INSERT|people|name=Chris,age=?,photo=?,bdate=?", 3, []byte("CPHOTO"), time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC))
+	return db
+}
+
+func exec(t *testing.T, db *sql.DB, query string, args ...any) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatalf("Exec of %q: %v", query, err)
+	}
+}
+
+func TestPing(t *testing.T) {
+	db := openDB(t)
+	if err := db.Ping(); err != nil {
+		t.Errorf("err was %#v, expected nil", err)
+	}
+}
+
+func TestQuery(t *testing.T) {
+	db := openDB(t)
+	rows, err := db.Query("SELECT|people|age,name|")
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	cols, err := rows.Columns()
+	if err != nil || !reflect.DeepEqual(cols, []string{"age", "name"}) {
+		t.Errorf("Columns = %v, %v", cols, err)
+	}
+	type row struct {
+		age  int
+		name string
+	}
+	got := []row{}
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.age, &r.name); err != nil {
+			t.Fatalf("Scan: %v", err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+	rows.Close()
+	want := []row{{1, "Alice"}, {2, "Bob"}, {3, "Chris"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mismatch.\n got: %#v\nwant: %#v", got, want)
+	}
+}
+
+func TestQueryRow(t *testing.T) {
+	db := openDB(t)
+	var name string
+	var age int
+	var photo []byte
+	var bdate sql.NullTime
+
+	if err := db.QueryRow("SELECT|people|age,name|age=?", 3).Scan(&age, &name); err != nil || name != "Chris" {
+		t.Errorf("age=3 gave %q, %v, want Chris", name, err)
+	}
+	if err := db.QueryRow("SELECT|people|photo,bdate|name=Alice").Scan(&photo, &bdate); err != nil {
+		t.Fatal(err)
+	}
+	if string(photo) != "APHOTO" || bdate.Valid {
+		t.Errorf("Alice photo = %q, bdate = %v, want APHOTO and NULL", photo, bdate)
+	}
+	if err := db.QueryRow("SELECT|people|bdate|name=Chris").Scan(&bdate); err != nil || bdate.Time.Year() != 2000 {
+		t.Errorf("Chris bdate = %v, %v", bdate, err)
+	}
+	if err := db.QueryRow("SELECT|people|age|name=?", "nobody").Scan(&age); err != sql.ErrNoRows {
+		t.Errorf("err = %v, want ErrNoRows", err)
+	}
+}
+
+func TestStatement(t *testing.T) {
+	db := openDB(t)
+	stmt, err := db.Prepare("SELECT|people|age|name=?")
+	if err != nil {
+		t.Fatalf("Prepare: %v", err)
+	}
+	var age int
+	for name, want := range map[string]int{"Alice": 1, "Bob": 2, "Chris": 3} {
+		if err := stmt.QueryRow(name).Scan(&age); err != nil || age != want {
+			t.Errorf("QueryRow(%q) = %d, %v, want %d", name, age, err, want)
+		}
+	}
+	if err := stmt.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	var name string
+	if err := stmt.QueryRow("foo").Scan(&name); err == nil {
+		t.Errorf("expected error from QueryRow.Scan after Stmt.Close")
+	}
+}
+
+func TestExec(t *testing.T) {
+	db := openDB(t)
+	exec(t, db, "CREATE|t1|name=string,age=int32,dead=bool")
+	stmt, err := db.Prepare("INSERT|t1|name=?,age=?")
+	if err != nil {
+		t.Fatalf("Stmt, err = %v, %v", stmt, err)
+	}
+	defer stmt.Close()
+
+	type execTest struct {
+		args    []any
+		wantErr string
+	}
+	execTests := []execTest{
+		// 正常插入
+		{[]any{"Brad", 31}, ""},
+		{[]any{"Brad", int64(31)}, ""},
+		{[]any{"Bob", "32"}, ""},
+
+		// 超出 int32
+		{[]any{"Brad", int64(0xFFFFFFFF)}, "overflows int32"},
+		{[]any{"Brad", "4294967295"}, "overflows int32"},
+
+		// 参数个数不对
+		{[]any{}, "sql: expected 2 arguments, got 0"},
+		{[]any{1, 2, 3}, "sql: expected 2 arguments, got 3"},
+
+		// 类型不对和非空列
+		{[]any{7, 9}, "cannot convert int64(7) to string"},
+		{[]any{nil, 1}, `column "name" is not nullable`},
+	}
+	for n, et := range execTests {
+		res, err := stmt.Exec(et.args...)
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+		if !strings.Contains(errStr, et.wantErr) || (et.wantErr == "") != (err == nil) {
+			t.Errorf("stmt.Execute #%d: for %v, got error %q, want error %q", n, et.args, errStr, et.wantErr)
+			continue
+		}
+		if err == nil {
+			if n, _ := res.RowsAffected(); n != 1 {
+				t.Errorf("RowsAffected = %d, want 1", n)
+			}
+		}
+	}
+
+	var count int
+	var dead bool
+	rows, _ := db.Query("SELECT|t1|dead|")
+	for rows.Next() {
+		if err := rows.Scan(&dead); err != nil || dead {
+			t.Errorf("dead = %t, %v, want zero value false", dead, err)
+		}
+		count++
+	}
+	if count != 3 {
+		t.Errorf("inserted %d rows, want 3", count)
+	}
+}
+
+func TestTx(t *testing.T) {
+	db := openDB(t)
+	exec(t, db, "CREATE|t1|name=string,age=int32,dead=bool")
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin = %v", err)
+	}
+	stmt, err := tx.Prepare("INSERT|t1|name=?,age=?")
+	if err != nil {
+		t.Fatalf("Stmt, err = %v, %v", stmt, err)
+	}
+	defer stmt.Close()
+	for _, args := range [][]any{{"Brad", 31}, {"Amy", 28}} {
+		if _, err := stmt.Exec(args...); err != nil {
+			t.Fatalf("Exec = %v", err)
+		}
+	}
+	if _, err := tx.Exec("CREATE|t2|id=int"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT|t2|id=?", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	// 事务内能看到自己的写入，事务外看不到
+	var n int
+	if err := tx.QueryRow("SELECT|t1|age|name=Amy").Scan(&n); err != nil || n != 28 {
+		t.Errorf("in tx: age = %d, %v, want 28", n, err)
+	}
+	if err := db.QueryRow("SELECT|t1|age|name=Amy").Scan(&n); err != sql.ErrNoRows {
+		t.Errorf("outside tx: err = %v, want ErrNoRows", err)
+	}
+	if _, err := db.Query("SELECT|t2|id|"); err == nil {
+		t.Error("table created in tx is visible before commit")
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit = %v", err)
+	}
+	if err := tx.Rollback(); err != sql.ErrTxDone {
+		t.Errorf("Rollback after Commit = %v, want ErrTxDone", err)
+	}
+	if err := db.QueryRow("SELECT|t1|age|name=Amy").Scan(&n); err != nil || n != 28 {
+		t.Errorf("after commit: age = %d, %v, want 28", n, err)
+	}
+	if err := db.QueryRow("SELECT|t2|id|").Scan(&n); err != nil || n != 1 {
+		t.Errorf("after commit: t2 id = %d, %v, want 1", n, err)
+	}
+}
+
+func TestTxRollback(t *testing.T) {
+	db := openDB(t)
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec("INSERT|people|name=Dave,age=4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := db.QueryRow("SELECT|people|age|name=Dave").Scan(&n); err != sql.ErrNoRows {
+		t.Errorf("rolled back row is visible: %d, %v", n, err)
+	}
+
+	ro, err := db.BeginTx(context.Background(), &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ro.Rollback()
+	if _, err := ro.Exec("INSERT|people|name=Eve,age=5"); err == nil {
+		t.Error("write in read-only tx succeeded")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	db := openDB(t)
+	for _, q := range []string{
+		"DROP|people",
+		"SELECT|people",
+		"CREATE|t|id=uuid",
+		"CREATE|t|id=int,id=int",
+		"CREATE|people|id=int",
+		"INSERT|nosuch|id=1",
+		"INSERT|people|nosuch=1",
+		"INSERT|people|name=x,age=old",
+		"SELECT|people|age|",
+	} {
+		if _, err := db.Exec(q); err == nil {
+			t.Errorf("Exec(%q) succeeded, want error", q)
+		}
+	}
+	if _, err := db.Query("SELECT|people|nosuch|"); err == nil {
+		t.Error("unknown select column succeeded")
+	}
+	if _, err := db.Query("INSERT|people|name=x"); err == nil {
+		t.Error("Query of INSERT succeeded")
+	}
+}
@@ -0,0 +1,108 @@
+package memdb
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+	语句使用 | 分隔各部分，和 database.go 中的写法一致：
+		CREATE|表名|列名=类型,列名=类型
+		INSERT|表名|列名=?,列名=字面值
+		SELECT|表名|列名,列名|列名=?,列名=字面值
+	SELECT 的列可以写 *，表示按建表顺序的全部列；最后的条件部分可以省略，多个条件之间是 AND；字面值 NULL 表示空值。
+	类型有 string、int32、int64、int、float64、bool、datetime、blob，加上 null 前缀（如 nullstring）表示可以为 NULL。
+*/
+
+type command struct {
+	verb  string // CREATE、INSERT、SELECT
+	table string
+	cols  []column // CREATE 的列定义
+	sets  []assign // INSERT 的赋值
+	sel   []string // SELECT 的列
+	where []assign // SELECT 的条件
+	nargs int      // ? 的个数
+}
+
+// assign 是 列名=? 或 列名=字面值
+type assign struct {
+	col string
+	arg int // 第几个 ?，字面值时为 -1
+	lit string
+}
+
+func parse(query string) (*command, error) {
+	parts := strings.Split(query, "|")
+	if len(parts) < 2 || parts[1] == "" {
+		return nil, fmt.Errorf("memdb: invalid query %q", query)
+	}
+	cmd := &command{verb: strings.ToUpper(parts[0]), table: parts[1]}
+	var err error
+	switch cmd.verb {
+	case "CREATE":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("memdb: CREATE wants 3 parts, got %d in %q", len(parts), query)
+		}
+		cmd.cols, err = parseColumns(parts[2])
+	case "INSERT":
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("memdb: INSERT wants 3 parts, got %d in %q", len(parts), query)
+		}
+		cmd.sets, err = cmd.parseAssigns(parts[2])
+	case "SELECT":
+		if len(parts) != 3 && len(parts) != 4 {
+			return nil, fmt.Errorf("memdb: SELECT wants 3 or 4 parts, got %d in %q", len(parts), query)
+		}
+		cmd.sel = strings.Split(parts[2], ",")
+		if len(parts) == 4 && parts[3] != "" {
+			cmd.where, err = cmd.parseAssigns(parts[3])
+		}
+	default:
+		return nil, fmt.Errorf("memdb: unsupported command %q", parts[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+func parseColumns(s string) ([]column, error) {
+	var cols []column
+	seen := make(map[string]bool)
+	for _, def := range strings.Split(s, ",") {
+		name, typ, ok := strings.Cut(def, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("memdb: invalid column definition %q", def)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("memdb: duplicate column %q", name)
+		}
+		seen[name] = true
+		c := column{name: name, typ: typ}
+		if t, ok := strings.CutPrefix(typ, "null"); ok {
+			c.typ, c.nullable = t, true
+		}
+		if !validType(c.typ) {
+			return nil, fmt.Errorf("memdb: unknown type %q for column %q", typ, name)
+		}
+		cols = append(cols, c)
+	}
+	return cols, nil
+}
+
+func (cmd *command) parseAssigns(s string) ([]assign, error) {
+	var out []assign
+	for _, kv := range strings.Split(s, ",") {
+		col, v, ok := strings.Cut(kv, "=")
+		if !ok || col == "" {
+			return nil, fmt.Errorf("memdb: invalid assignment %q", kv)
+		}
+		a := assign{col: col, arg: -1, lit: v}
+		if v == "?" {
+			a.arg = cmd.nargs
+			cmd.nargs++
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
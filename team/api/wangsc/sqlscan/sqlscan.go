@@ -0,0 +1,320 @@
+/**
+ * sqlscan 按 rows.Columns() 的列名把查询结果扫描到结构体，不用再像 testQuery 那样逐个写 rows.Scan(&r.age, &r.name)：
+ *   rows, err := db.Query("SELECT|people|age,name|")
+ *   ...
+ *   people, err := sqlscan.ScanAll[Person](rows)
+ * 列和字段的对应规则：
+ *   1.字段的 db:"col" 标签，没有标签时用字段名，不区分大小写；db:"-" 和未导出的字段被忽略
+ *   2.没有标签的嵌入结构体（包括指针）展开，其字段和外层字段一样参与匹配，重名时浅层优先、同层先声明的优先
+ *   3.NULL：指针字段为 nil，sql.NullString 等实现了 sql.Scanner 的字段自行处理，其它字段为零值
+ *   4.结构体中没有对应字段的列默认丢弃，使用 Strict() 时返回错误
+ * T 是结构体指针时每行新分配一个结构体；T 不是结构体（或者是 time.Time、实现了 sql.Scanner 的类型）时，
+ * 查询必须只有一列，直接扫描到 T。
+ * *sql.Row 不提供列名，无法按列名扫描，代替 db.QueryRow 的是 QueryOne：
+ *   p, err := sqlscan.QueryOne[Person](ctx, db, "SELECT|people|age,name|name=?", []any{"Bob"}, sqlscan.Strict())
+ */
+
+package sqlscan
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"iter"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rows 是 *sql.Rows 中扫描需要的方法
+type Rows interface {
+	Columns() ([]string, error)
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close() error
+}
+
+// Option 是扫描的选项
+type Option func(*config)
+
+type config struct {
+	strict bool
+}
+
+// Strict 使结构体中没有对应字段的列成为错误，默认丢弃这些列
+func Strict() Option {
+	return func(c *config) { c.strict = true }
+}
+
+// ScanAll 扫描全部行并关闭 rows
+func ScanAll[T any](rows Rows, opts ...Option) ([]T, error) {
+	var out []T
+	for v, err := range Iter[T](rows, opts...) {
+		if err != nil {
+			return out, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// ScanOne 扫描第一行并关闭 rows，没有结果时返回 sql.ErrNoRows
+func ScanOne[T any](rows Rows, opts ...Option) (T, error) {
+	var zero T
+	for v, err := range Iter[T](rows, opts...) {
+		return v, err
+	}
+	return zero, sql.ErrNoRows
+}
+
+// Querier 是 *sql.DB、*sql.Tx、*sql.Conn 共有的查询方法
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// QueryOne 以 args 执行查询并扫描第一行，没有结果时返回 sql.ErrNoRows，用法同 QueryRow(...).Scan。
+// 选项放在最后，所以查询参数用切片传入，没有参数时为 nil
+func QueryOne[T any](ctx context.Context, q Querier, query string, args []any, opts ...Option) (T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return ScanOne[T](rows, opts...)
+}
+
+// QueryAll 以 args 执行查询并扫描全部行，参数同 QueryOne
+func QueryAll[T any](ctx context.Context, q Querier, query string, args []any, opts ...Option) ([]T, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ScanAll[T](rows, opts...)
+}
+
+// Iter 逐行扫描，出错时产出错误后结束；结束或提前 break 时关闭 rows
+func Iter[T any](rows Rows, opts ...Option) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		defer rows.Close()
+		var zero T
+		var c config
+		for _, o := range opts {
+			o(&c)
+		}
+		cols, err := rows.Columns()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		// T 是结构体指针时按结构体扫描
+		t := reflect.TypeFor[T]()
+		alloc := t.Kind() == reflect.Pointer && !scalar(t.Elem())
+		if alloc {
+			t = t.Elem()
+		}
+		p, err := newPlan(t, cols, c)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		for rows.Next() {
+			var v T
+			rv := reflect.ValueOf(&v).Elem()
+			if alloc {
+				rv.Set(reflect.New(t))
+				rv = rv.Elem()
+			}
+			if err := p.scan(rows, rv); err != nil {
+				yield(zero, err)
+				return
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(zero, err)
+		}
+	}
+}
+
+// field 是结构体中可以接收一列的字段
+type field struct {
+	index []int // 用于 FieldByIndex，可能经过嵌入的指针
+}
+
+var fieldCache sync.Map // reflect.Type -> map[string]field
+
+var (
+	scannerType = reflect.TypeFor[sql.Scanner]()
+	timeType    = reflect.TypeFor[time.Time]()
+)
+
+// scalar 返回 t 是否整体接收一列
+func scalar(t reflect.Type) bool {
+	return t.Kind() != reflect.Struct || t == timeType || reflect.PointerTo(t).Implements(scannerType)
+}
+
+// fields 返回 t 的列名（小写）到字段的映射
+func fields(t reflect.Type) map[string]field {
+	if m, ok := fieldCache.Load(t); ok {
+		return m.(map[string]field)
+	}
+	m := make(map[string]field)
+	// 按层展开，浅层的字段先放进 m
+	type level struct {
+		t     reflect.Type
+		index []int
+	}
+	cur := []level{{t, nil}}
+	seen := map[reflect.Type]bool{t: true}
+	for len(cur) > 0 {
+		var next []level
+		for _, l := range cur {
+			for i := 0; i < l.t.NumField(); i++ {
+				sf := l.t.Field(i)
+				tag, hasTag := sf.Tag.Lookup("db")
+				if tag == "-" {
+					continue
+				}
+				index := append(append([]int(nil), l.index...), i)
+				ft := sf.Type
+				if ft.Kind() == reflect.Pointer {
+					ft = ft.Elem()
+				}
+				if sf.Anonymous && !hasTag && ft.Kind() == reflect.Struct && !scalar(ft) {
+					if !seen[ft] {
+						seen[ft] = true
+						next = append(next, level{ft, index})
+					}
+					continue
+				}
+				if !sf.IsExported() {
+					continue
+				}
+				name := sf.Name
+				if tag != "" {
+					name = tag
+				}
+				name = strings.ToLower(name)
+				// 浅层的、同层先声明的字段已经在 m 中
+				if _, ok := m[name]; ok {
+					continue
+				}
+				m[name] = field{index: index}
+			}
+		}
+		cur = next
+	}
+	fieldCache.Store(t, m)
+	return m
+}
+
+// plan 是一次查询的列到字段的对应，列不变时每行复用
+type plan struct {
+	scalar bool
+	fields []*field // 与列一一对应，nil 表示丢弃
+}
+
+func newPlan(t reflect.Type, cols []string, c config) (*plan, error) {
+	if scalar(t) {
+		if len(cols) != 1 {
+			return nil, fmt.Errorf("sqlscan: scanning into %v needs 1 column, got %d", t, len(cols))
+		}
+		return &plan{scalar: true}, nil
+	}
+	m := fields(t)
+	p := &plan{fields: make([]*field, len(cols))}
+	var unknown []string
+	for i, col := range cols {
+		if f, ok := m[strings.ToLower(col)]; ok {
+			p.fields[i] = &f
+		} else {
+			unknown = append(unknown, col)
+		}
+	}
+	if c.strict && len(unknown) > 0 {
+		return nil, fmt.Errorf("sqlscan: no field in %v for columns %s", t, strings.Join(unknown, ", "))
+	}
+	return p, nil
+}
+
+func (p *plan) scan(rows Rows, v reflect.Value) error {
+	if p.scalar {
+		n := newNullable(v)
+		if err := rows.Scan(n.dest); err != nil {
+			return err
+		}
+		n.store()
+		return nil
+	}
+	dest := make([]any, len(p.fields))
+	ns := make([]nullable, 0, len(p.fields))
+	for i, f := range p.fields {
+		if f == nil {
+			dest[i] = new(any)
+			continue
+		}
+		fv, err := fieldByIndex(v, f.index)
+		if err != nil {
+			return err
+		}
+		n := newNullable(fv)
+		ns = append(ns, n)
+		dest[i] = n.dest
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	for _, n := range ns {
+		n.store()
+	}
+	return nil
+}
+
+// nullable 让普通字段可以接收 NULL：先扫描到 **T，非 NULL 时再复制到字段
+type nullable struct {
+	field reflect.Value
+	ptr   reflect.Value // *T，只在需要中转时有效
+	dest  any
+}
+
+func newNullable(fv reflect.Value) nullable {
+	t := fv.Type()
+	if t.Kind() == reflect.Pointer || reflect.PointerTo(t).Implements(scannerType) {
+		return nullable{field: fv, dest: fv.Addr().Interface()}
+	}
+	pp := reflect.New(reflect.PointerTo(t)) // **T
+	return nullable{field: fv, ptr: pp.Elem(), dest: pp.Interface()}
+}
+
+func (n nullable) store() {
+	if !n.ptr.IsValid() {
+		return
+	}
+	if n.ptr.IsNil() {
+		n.field.SetZero()
+	} else {
+		n.field.Set(n.ptr.Elem())
+	}
+}
+
+// fieldByIndex 同 FieldByIndex，经过为 nil 的嵌入指针时分配
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, errors.New("sqlscan: cannot set embedded pointer to unexported struct")
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
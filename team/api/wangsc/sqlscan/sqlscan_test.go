@@ -0,0 +1,234 @@
+package sqlscan
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ZcmOrg/demo-go-base/team/api/wangsc/memdb"
+)
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	name := t.Name()
+	db, err := sql.Open("memdb", name)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() {
+		db.Close()
+		memdb.Drop(name)
+	})
+	for _, q := range []string{
+		"CREATE|people|name=string,age=int32,photo=nullblob,bdate=nulldatetime,nick=nullstring,score=nullfloat64",
+		"INSERT|people|name=Alice,age=1,photo=APHOTO,nick=Al,score=1.5",
+		"INSERT|people|name=Bob,age=2",
+		"INSERT|people|name=Chris,age=3,bdate=2000-01-02T03:04:05Z",
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("Exec of %q: %v", q, err)
+		}
+	}
+	return db
+}
+
+func query(t *testing.T, db *sql.DB, q string, args ...any) *sql.Rows {
+	t.Helper()
+	rows, err := db.Query(q, args...)
+	if err != nil {
+		t.Fatalf("Query %q: %v", q, err)
+	}
+	return rows
+}
+
+type Person struct {
+	Name string
+	Age  int `db:"age"`
+}
+
+func TestScanAll(t *testing.T) {
+	db := openDB(t)
+	got, err := ScanAll[Person](query(t, db, "SELECT|people|age,name|"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Person{{"Alice", 1}, {"Bob", 2}, {"Chris", 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("mismatch.\n got: %#v\nwant: %#v", got, want)
+	}
+
+	// 非结构体：只能有一列
+	ages, err := ScanAll[int](query(t, db, "SELECT|people|age|"))
+	if err != nil || !reflect.DeepEqual(ages, []int{1, 2, 3}) {
+		t.Errorf("ages = %v, %v", ages, err)
+	}
+	if _, err := ScanAll[int](query(t, db, "SELECT|people|age,name|")); err == nil {
+		t.Error("scanning 2 columns into int succeeded")
+	}
+}
+
+func TestNullable(t *testing.T) {
+	type row struct {
+		Name  string
+		Photo []byte         // NULL 时为 nil
+		BDate *time.Time     `db:"bdate"`
+		Nick  sql.NullString // sql.Scanner 自行处理
+		Score float64        // NULL 时为零值
+	}
+	db := openDB(t)
+	got, err := ScanAll[row](query(t, db, "SELECT|people|*|"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b, c := got[0], got[1], got[2]
+	if string(a.Photo) != "APHOTO" || a.BDate != nil || a.Nick != (sql.NullString{String: "Al", Valid: true}) || a.Score != 1.5 {
+		t.Errorf("Alice = %+v", a)
+	}
+	if b.Photo != nil || b.BDate != nil || b.Nick.Valid || b.Score != 0 {
+		t.Errorf("Bob = %+v, want NULL columns as nil/invalid/zero", b)
+	}
+	if c.BDate == nil || c.BDate.Year() != 2000 {
+		t.Errorf("Chris bdate = %v", c.BDate)
+	}
+}
+
+type Audit struct {
+	Name    string `db:"nick"` // 标签改了列名，和外层的 Name 不冲突
+	Created time.Time
+}
+
+type Stats struct {
+	Score float64
+}
+
+func TestEmbedded(t *testing.T) {
+	type row struct {
+		Person
+		*Stats
+		Audit
+		Name string `db:"name"` // 浅层优先，Person.Name 拿不到 name 列
+	}
+	db := openDB(t)
+	got, err := ScanOne[row](query(t, db, "SELECT|people|name,age,score,nick|name=?", "Alice"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Alice" || got.Person.Name != "" || got.Age != 1 || got.Stats == nil || got.Score != 1.5 || got.Audit.Name != "Al" {
+		t.Errorf("got %+v, stats %+v", got, got.Stats)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	db := openDB(t)
+	// 默认丢弃没有字段的列
+	if _, err := ScanAll[Person](query(t, db, "SELECT|people|name,age,photo|")); err != nil {
+		t.Errorf("lenient scan: %v", err)
+	}
+	_, err := ScanAll[Person](query(t, db, "SELECT|people|name,age,photo,nick|"), Strict())
+	if err == nil || !strings.Contains(err.Error(), "photo, nick") {
+		t.Errorf("strict scan err = %v, want unknown columns photo, nick", err)
+	}
+	if _, err := ScanAll[Person](query(t, db, "SELECT|people|name,age|"), Strict()); err != nil {
+		t.Errorf("strict scan with known columns: %v", err)
+	}
+}
+
+func TestScanOne(t *testing.T) {
+	db := openDB(t)
+	p, err := ScanOne[Person](query(t, db, "SELECT|people|name,age|age=?", 2))
+	if err != nil || p != (Person{"Bob", 2}) {
+		t.Errorf("ScanOne = %+v, %v", p, err)
+	}
+	if _, err := ScanOne[Person](query(t, db, "SELECT|people|name,age|age=?", 9)); err != sql.ErrNoRows {
+		t.Errorf("err = %v, want ErrNoRows", err)
+	}
+}
+
+func TestPointerToStruct(t *testing.T) {
+	db := openDB(t)
+	got, err := ScanAll[*Person](query(t, db, "SELECT|people|name,age|"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 3 || *got[0] != (Person{"Alice", 1}) || *got[2] != (Person{"Chris", 3}) || got[0] == got[1] {
+		t.Errorf("got %v, want a new *Person per row", got)
+	}
+	// 指向非结构体的指针仍然只接收一列，NULL 时为 nil
+	dates, err := ScanAll[*time.Time](query(t, db, "SELECT|people|bdate|"))
+	if err != nil || len(dates) != 3 || dates[0] != nil || dates[2].Year() != 2000 {
+		t.Errorf("dates = %v, %v", dates, err)
+	}
+}
+
+func TestQueryOne(t *testing.T) {
+	db := openDB(t)
+	ctx := context.Background()
+	p, err := QueryOne[Person](ctx, db, "SELECT|people|name,age|name=?", []any{"Chris"})
+	if err != nil || p != (Person{"Chris", 3}) {
+		t.Errorf("QueryOne = %+v, %v", p, err)
+	}
+	if _, err := QueryOne[*Person](ctx, db, "SELECT|people|name,age|name=?", []any{"nobody"}); err != sql.ErrNoRows {
+		t.Errorf("err = %v, want ErrNoRows", err)
+	}
+	if _, err := QueryOne[Person](ctx, db, "SELECT|nosuch|name|", nil); err == nil {
+		t.Error("query of unknown table succeeded")
+	}
+	// 选项传给扫描
+	if _, err := QueryOne[Person](ctx, db, "SELECT|people|name,age,nick|name=?", []any{"Alice"}, Strict()); err == nil || !strings.Contains(err.Error(), "nick") {
+		t.Errorf("strict QueryOne err = %v, want unknown column nick", err)
+	}
+	if _, err := QueryAll[Person](ctx, db, "SELECT|people|name,age,nick|", nil, Strict()); err == nil {
+		t.Error("strict QueryAll with unknown column succeeded")
+	}
+
+	// *sql.Tx 同样可以使用
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("INSERT|people|name=Dave,age=4"); err != nil {
+		t.Fatal(err)
+	}
+	ages, err := QueryAll[int](ctx, tx, "SELECT|people|age|", nil)
+	if err != nil || !reflect.DeepEqual(ages, []int{1, 2, 3, 4}) {
+		t.Errorf("ages in tx = %v, %v", ages, err)
+	}
+}
+
+func TestIter(t *testing.T) {
+	db := openDB(t)
+	rows := query(t, db, "SELECT|people|name,age|")
+	var names []string
+	for p, err := range Iter[Person](rows) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, p.Name)
+		if len(names) == 2 {
+			break
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"Alice", "Bob"}) {
+		t.Errorf("names = %v", names)
+	}
+	// 提前 break 后 rows 已经关闭
+	if rows.Next() {
+		t.Error("rows still open after break")
+	}
+
+	// 扫描出错时产出错误并结束
+	var n int
+	for _, err := range Iter[struct{ Name int }](query(t, db, "SELECT|people|name|")) {
+		n++
+		if err == nil {
+			t.Error("scanning string into int succeeded")
+		}
+	}
+	if n != 1 {
+		t.Errorf("iterations = %d, want 1", n)
+	}
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+/*
+	LRU 和 LFU 缓存，都建立在 container/list 之上：
+		1.LRU 用一条链表，命中时 MoveToFront，淘汰 Back；
+		2.LFU 按访问次数分桶，每个桶是一条链表（桶内按 LRU 顺序），淘汰最低频桶的 Back，
+		  Get/Put 都是 O(1)；
+		3.容量满时淘汰一个元素并调用 OnEvict 回调；
+		4.PutTTL 可以给单个元素设置过期时间，过期的元素在访问时被删除；
+		5.Stats 返回命中/未命中次数；
+		6.LRU/LFU 本身不是并发安全的，需要并发访问时用 NewSyncCache 包一层。
+*/
+
+// CacheStats 是缓存的命中统计
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+}
+
+// Cache 是 LRU 和 LFU 共同的方法
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Put(key K, value V)
+	PutTTL(key K, value V, ttl time.Duration)
+	Delete(key K) bool
+	Len() int
+	Stats() CacheStats
+}
+
+// CacheOptions 是创建缓存时的可选项
+type CacheOptions[K comparable, V any] struct {
+	// OnEvict 在元素因容量不足或过期被淘汰时调用，Delete 和覆盖写不会调用。
+	// 使用 SyncCache 时回调在锁内执行，回调里不能再访问这个缓存
+	OnEvict func(key K, value V)
+	// Now 返回当前时间，默认 time.Now，测试时可以替换
+	Now func() time.Time
+}
+
+func (o *CacheOptions[K, V]) now() time.Time {
+	if o.Now != nil {
+		return o.Now()
+	}
+	return time.Now()
+}
+
+func (o *CacheOptions[K, V]) evict(key K, value V) {
+	if o.OnEvict != nil {
+		o.OnEvict(key, value)
+	}
+}
+
+type cacheEntry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time // 零值表示不过期
+	freq    int       // 仅 LFU 使用
+}
+
+func (e *cacheEntry[K, V]) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+// LRU 是最近最少使用淘汰的缓存
+type LRU[K comparable, V any] struct {
+	capacity int
+	ll       *list.List
+	items    map[K]*list.Element
+	stats    CacheStats
+	opts     CacheOptions[K, V]
+}
+
+// NewLRU 创建容量为 capacity 的 LRU 缓存，capacity 必须大于 0
+func NewLRU[K comparable, V any](capacity int, opts *CacheOptions[K, V]) *LRU[K, V] {
+	if capacity <= 0 {
+		panic("container: cache capacity must be positive")
+	}
+	c := &LRU[K, V]{capacity: capacity, ll: list.New(), items: make(map[K]*list.Element)}
+	if opts != nil {
+		c.opts = *opts
+	}
+	return c
+}
+
+// Get 返回 key 对应的值，并把它移到链表头部
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*cacheEntry[K, V])
+		if !ent.expired(c.opts.now()) {
+			c.stats.Hits++
+			c.ll.MoveToFront(e)
+			return ent.value, true
+		}
+		c.removeElement(e, true)
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// Put 写入一个不过期的元素
+func (c *LRU[K, V]) Put(key K, value V) {
+	c.put(key, value, time.Time{})
+}
+
+// PutTTL 写入一个 ttl 后过期的元素
+func (c *LRU[K, V]) PutTTL(key K, value V, ttl time.Duration) {
+	c.put(key, value, c.opts.now().Add(ttl))
+}
+
+func (c *LRU[K, V]) put(key K, value V, expires time.Time) {
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*cacheEntry[K, V])
+		ent.value, ent.expires = value, expires
+		c.ll.MoveToFront(e)
+		return
+	}
+	if c.ll.Len() >= c.capacity {
+		c.removeElement(c.ll.Back(), true)
+	}
+	c.items[key] = c.ll.PushFront(&cacheEntry[K, V]{key: key, value: value, expires: expires})
+}
+
+// Delete 删除 key，返回 key 是否存在
+func (c *LRU[K, V]) Delete(key K) bool {
+	e, ok := c.items[key]
+	if ok {
+		c.removeElement(e, false)
+	}
+	return ok
+}
+
+func (c *LRU[K, V]) removeElement(e *list.Element, evicted bool) {
+	ent := c.ll.Remove(e).(*cacheEntry[K, V])
+	delete(c.items, ent.key)
+	if evicted {
+		c.stats.Evictions++
+		c.opts.evict(ent.key, ent.value)
+	}
+}
+
+// Len 返回元素个数（包括已过期但尚未被访问到的元素）
+func (c *LRU[K, V]) Len() int {
+	return c.ll.Len()
+}
+
+// Stats 返回命中统计
+func (c *LRU[K, V]) Stats() CacheStats {
+	return c.stats
+}
+
+// LFU 是最不经常使用淘汰的缓存，访问次数相同时淘汰最久未访问的元素
+type LFU[K comparable, V any] struct {
+	capacity int
+	items    map[K]*list.Element
+	freqs    map[int]*list.List // 访问次数 -> 该次数的元素链表
+	minFreq  int
+	stats    CacheStats
+	opts     CacheOptions[K, V]
+}
+
+// NewLFU 创建容量为 capacity 的 LFU 缓存，capacity 必须大于 0
+func NewLFU[K comparable, V any](capacity int, opts *CacheOptions[K, V]) *LFU[K, V] {
+	if capacity <= 0 {
+		panic("container: cache capacity must be positive")
+	}
+	c := &LFU[K, V]{capacity: capacity, items: make(map[K]*list.Element), freqs: make(map[int]*list.List)}
+	if opts != nil {
+		c.opts = *opts
+	}
+	return c
+}
+
+// Get 返回 key 对应的值，并把它的访问次数加一
+func (c *LFU[K, V]) Get(key K) (V, bool) {
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*cacheEntry[K, V])
+		if !ent.expired(c.opts.now()) {
+			c.stats.Hits++
+			c.touch(e)
+			return ent.value, true
+		}
+		c.removeElement(e, true)
+	}
+	c.stats.Misses++
+	var zero V
+	return zero, false
+}
+
+// Put 写入一个不过期的元素
+func (c *LFU[K, V]) Put(key K, value V) {
+	c.put(key, value, time.Time{})
+}
+
+// PutTTL 写入一个 ttl 后过期的元素
+func (c *LFU[K, V]) PutTTL(key K, value V, ttl time.Duration) {
+	c.put(key, value, c.opts.now().Add(ttl))
+}
+
+func (c *LFU[K, V]) put(key K, value V, expires time.Time) {
+	if e, ok := c.items[key]; ok {
+		ent := e.Value.(*cacheEntry[K, V])
+		ent.value, ent.expires = value, expires
+		c.touch(e)
+		return
+	}
+	if len(c.items) >= c.capacity {
+		c.removeElement(c.freqs[c.minFreq].Back(), true)
+	}
+	ent := &cacheEntry[K, V]{key: key, value: value, expires: expires, freq: 1}
+	c.items[key] = c.bucket(1).PushFront(ent)
+	c.minFreq = 1
+}
+
+// touch 把元素移到访问次数加一的桶里
+func (c *LFU[K, V]) touch(e *list.Element) {
+	ent := e.Value.(*cacheEntry[K, V])
+	c.unlink(e)
+	if ent.freq == c.minFreq && c.freqs[ent.freq] == nil {
+		c.minFreq++
+	}
+	ent.freq++
+	c.items[ent.key] = c.bucket(ent.freq).PushFront(ent)
+}
+
+func (c *LFU[K, V]) bucket(freq int) *list.List {
+	l, ok := c.freqs[freq]
+	if !ok {
+		l = list.New()
+		c.freqs[freq] = l
+	}
+	return l
+}
+
+// unlink 把元素从所在的桶里摘下，桶空了就删掉
+func (c *LFU[K, V]) unlink(e *list.Element) {
+	ent := e.Value.(*cacheEntry[K, V])
+	l := c.freqs[ent.freq]
+	l.Remove(e)
+	if l.Len() == 0 {
+		delete(c.freqs, ent.freq)
+	}
+}
+
+// Delete 删除 key，返回 key 是否存在
+func (c *LFU[K, V]) Delete(key K) bool {
+	e, ok := c.items[key]
+	if ok {
+		c.removeElement(e, false)
+	}
+	return ok
+}
+
+func (c *LFU[K, V]) removeElement(e *list.Element, evicted bool) {
+	ent := e.Value.(*cacheEntry[K, V])
+	c.unlink(e)
+	delete(c.items, ent.key)
+	if ent.freq == c.minFreq && c.freqs[ent.freq] == nil {
+		c.minFreq = c.lowestFreq()
+	}
+	if evicted {
+		c.stats.Evictions++
+		c.opts.evict(ent.key, ent.value)
+	}
+}
+
+// lowestFreq 在删除元素后重新找最低的访问次数，桶的个数通常很少
+func (c *LFU[K, V]) lowestFreq() int {
+	lowest := 0
+	for f := range c.freqs {
+		if lowest == 0 || f < lowest {
+			lowest = f
+		}
+	}
+	return lowest
+}
+
+// Len 返回元素个数（包括已过期但尚未被访问到的元素）
+func (c *LFU[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Stats 返回命中统计
+func (c *LFU[K, V]) Stats() CacheStats {
+	return c.stats
+}
+
+// SyncCache 给 Cache 加上互斥锁，可以在多个 goroutine 中使用。
+// LRU/LFU 的 Get 也会修改内部链表，所以读写都要加同一把锁
+type SyncCache[K comparable, V any] struct {
+	mu sync.Mutex
+	c  Cache[K, V]
+}
+
+// NewSyncCache 返回并发安全的 c，之后不要再直接访问 c
+func NewSyncCache[K comparable, V any](c Cache[K, V]) *SyncCache[K, V] {
+	return &SyncCache[K, V]{c: c}
+}
+
+func (s *SyncCache[K, V]) Get(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Get(key)
+}
+
+func (s *SyncCache[K, V]) Put(key K, value V) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.Put(key, value)
+}
+
+func (s *SyncCache[K, V]) PutTTL(key K, value V, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.c.PutTTL(key, value, ttl)
+}
+
+func (s *SyncCache[K, V]) Delete(key K) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Delete(key)
+}
+
+func (s *SyncCache[K, V]) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Len()
+}
+
+func (s *SyncCache[K, V]) Stats() CacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.c.Stats()
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeNow struct{ t time.Time }
+
+func (f *fakeNow) Now() time.Time { return f.t }
+
+func TestLRU(t *testing.T) {
+	var evicted []string
+	c := NewLRU(2, &CacheOptions[string, int]{OnEvict: func(k string, _ int) { evicted = append(evicted, k) }})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a") // a 变成最近使用
+	c.Put("c", 3)
+	if _, ok := c.Get("b"); ok {
+		t.Error("b should have been evicted")
+	}
+	if v, ok := c.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %d, %v", v, ok)
+	}
+	c.Put("a", 10) // 覆盖写不触发淘汰
+	c.Delete("c")  // 删除不触发淘汰
+	if !reflect.DeepEqual(evicted, []string{"b"}) {
+		t.Errorf("evicted = %v, want [b]", evicted)
+	}
+	if got, want := c.Stats(), (CacheStats{Hits: 2, Misses: 1, Evictions: 1}); got != want {
+		t.Errorf("Stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestLFU(t *testing.T) {
+	var evicted []string
+	c := NewLFU(3, &CacheOptions[string, int]{OnEvict: func(k string, _ int) { evicted = append(evicted, k) }})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	c.Put("d", 4) // c 访问次数最少
+	c.Put("e", 5) // d 只访问过一次
+	c.Get("e")
+	c.Get("e")
+	c.Get("e")
+	c.Put("f", 6) // 剩下 a:3 b:2 e:4，淘汰 b
+	if want := []string{"c", "d", "b"}; !reflect.DeepEqual(evicted, want) {
+		t.Errorf("evicted = %v, want %v", evicted, want)
+	}
+	for _, k := range []string{"a", "e", "f"} {
+		if _, ok := c.Get(k); !ok {
+			t.Errorf("Get(%s) missed", k)
+		}
+	}
+	if c.Len() != 3 {
+		t.Errorf("Len = %d, want 3", c.Len())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	now := &fakeNow{time.Unix(0, 0)}
+	for name, c := range map[string]Cache[string, int]{
+		"lru": NewLRU(4, &CacheOptions[string, int]{Now: now.Now}),
+		"lfu": NewLFU(4, &CacheOptions[string, int]{Now: now.Now}),
+	} {
+		now.t = time.Unix(0, 0)
+		c.PutTTL("a", 1, time.Second)
+		c.Put("b", 2)
+		if _, ok := c.Get("a"); !ok {
+			t.Errorf("%s: a expired too early", name)
+		}
+		now.t = now.t.Add(time.Second)
+		if _, ok := c.Get("a"); ok {
+			t.Errorf("%s: a should have expired", name)
+		}
+		if _, ok := c.Get("b"); !ok {
+			t.Errorf("%s: b should not expire", name)
+		}
+		if c.Len() != 1 || c.Stats().Evictions != 1 {
+			t.Errorf("%s: Len = %d, Stats = %+v", name, c.Len(), c.Stats())
+		}
+	}
+}
+
+func TestSyncCache(t *testing.T) {
+	c := NewSyncCache[int, int](NewLFU[int, int](64, nil))
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				c.Put(i%100, g)
+				c.Get((i + g) % 100)
+			}
+		}(g)
+	}
+	wg.Wait()
+	if c.Len() != 64 {
+		t.Errorf("Len = %d, want 64", c.Len())
+	}
+	if s := c.Stats(); s.Hits+s.Misses != 8000 {
+		t.Errorf("Stats = %+v", s)
+	}
+}
+
+// mutexMap 是对比用的基准：普通 map 加互斥锁，没有容量限制
+type mutexMap struct {
+	mu sync.Mutex
+	m  map[string]int
+}
+
+func (c *mutexMap) Get(k string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.m[k]
+	return v, ok
+}
+
+func (c *mutexMap) Put(k string, v int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[k] = v
+}
+
+var benchKeys = func() []string {
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = strconv.Itoa(i)
+	}
+	return keys
+}()
+
+func benchmarkCache(b *testing.B, get func(string) (int, bool), put func(string, int)) {
+	b.RunParallel(func(pb *testing.PB) {
+		i := 0
+		for pb.Next() {
+			k := benchKeys[i%len(benchKeys)]
+			if _, ok := get(k); !ok {
+				put(k, i)
+			}
+			i++
+		}
+	})
+}
+
+func BenchmarkMutexMap(b *testing.B) {
+	c := &mutexMap{m: make(map[string]int)}
+	benchmarkCache(b, c.Get, c.Put)
+}
+
+func BenchmarkSyncLRU(b *testing.B) {
+	c := NewSyncCache[string, int](NewLRU[string, int](1024, nil))
+	benchmarkCache(b, c.Get, c.Put)
+}
+
+func BenchmarkSyncLFU(b *testing.B) {
+	c := NewSyncCache[string, int](NewLFU[string, int](1024, nil))
+	benchmarkCache(b, c.Get, c.Put)
+}
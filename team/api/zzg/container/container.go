@@ -74,6 +74,14 @@ func main() {
 		heap.Push(&a, 8)
 		fmt.Println("up", a)
 	}
+	{ //泛型堆的例子，不需要实现5个接口
+		h := NewHeap(func(a, b int) bool { return a < b }, 6, 2, 3, 1, 5, 4)
+		fmt.Println(h.Pop())
+		fmt.Println(h.Pop())
+		h.Push(0)
+		h.Push(8)
+		fmt.Println("peek", h.Peek(), "len", h.Len())
+	}
 	{ //list 的例子
 		//创建一个链表
 		l := list.New()
@@ -0,0 +1,160 @@
+package main
+
+// Deque 是双端队列，底层是可增长的环形数组：
+// 两端的插入删除均摊 O(1)，支持按下标访问，比 list.List 少了每个元素一次的内存分配。
+// 零值可以直接使用，非并发安全
+type Deque[T any] struct {
+	buf  []T // 长度总是 0 或 2 的幂
+	head int // 第一个元素的下标
+	n    int
+}
+
+const dequeMinCap = 8
+
+// Len 返回元素个数
+func (d *Deque[T]) Len() int {
+	return d.n
+}
+
+func (d *Deque[T]) mask() int {
+	return len(d.buf) - 1
+}
+
+// grow 容量翻倍，元素按顺序搬到新数组开头
+func (d *Deque[T]) grow() {
+	size := len(d.buf) * 2
+	if size == 0 {
+		size = dequeMinCap
+	}
+	buf := make([]T, size)
+	if d.n > 0 {
+		if d.head+d.n <= len(d.buf) {
+			copy(buf, d.buf[d.head:d.head+d.n])
+		} else {
+			k := copy(buf, d.buf[d.head:])
+			copy(buf[k:], d.buf[:d.n-k])
+		}
+	}
+	d.buf, d.head = buf, 0
+}
+
+// PushBack 在尾部插入
+func (d *Deque[T]) PushBack(x T) {
+	if d.n == len(d.buf) {
+		d.grow()
+	}
+	d.buf[(d.head+d.n)&d.mask()] = x
+	d.n++
+}
+
+// PushFront 在头部插入
+func (d *Deque[T]) PushFront(x T) {
+	if d.n == len(d.buf) {
+		d.grow()
+	}
+	d.head = (d.head - 1) & d.mask()
+	d.buf[d.head] = x
+	d.n++
+}
+
+// PopFront 删除并返回头部元素，队列为空时返回 false
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	x := d.buf[d.head]
+	d.buf[d.head] = zero
+	d.head = (d.head + 1) & d.mask()
+	d.n--
+	return x, true
+}
+
+// PopBack 删除并返回尾部元素，队列为空时返回 false
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	i := (d.head + d.n - 1) & d.mask()
+	x := d.buf[i]
+	d.buf[i] = zero
+	d.n--
+	return x, true
+}
+
+// Front 返回头部元素，队列为空时返回 false
+func (d *Deque[T]) Front() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back 返回尾部元素，队列为空时返回 false
+func (d *Deque[T]) Back() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[(d.head+d.n-1)&d.mask()], true
+}
+
+// At 返回第 i 个元素，下标越界时 panic
+func (d *Deque[T]) At(i int) T {
+	d.check(i)
+	return d.buf[(d.head+i)&d.mask()]
+}
+
+// Set 修改第 i 个元素，下标越界时 panic
+func (d *Deque[T]) Set(i int, x T) {
+	d.check(i)
+	d.buf[(d.head+i)&d.mask()] = x
+}
+
+func (d *Deque[T]) check(i int) {
+	if i < 0 || i >= d.n {
+		panic("container: deque index out of range")
+	}
+}
+
+// Rotate 与 ring.Move 的方向一致：n > 0 时把头部的 n 个元素依次移到尾部，
+// n < 0 时把尾部的 -n 个元素依次移到头部。移动次数取 n 对长度的模和它的补数中较小者
+func (d *Deque[T]) Rotate(n int) {
+	if d.n <= 1 {
+		return
+	}
+	n %= d.n
+	if n < 0 {
+		n += d.n
+	}
+	if n == 0 {
+		return
+	}
+	if d.n == len(d.buf) {
+		// 数组已满，直接移动 head 即可
+		d.head = (d.head + n) & d.mask()
+		return
+	}
+	if n <= d.n/2 {
+		for ; n > 0; n-- {
+			x, _ := d.PopFront()
+			d.PushBack(x)
+		}
+		return
+	}
+	for n = d.n - n; n > 0; n-- {
+		x, _ := d.PopBack()
+		d.PushFront(x)
+	}
+}
+
+// Clear 清空队列，保留已分配的空间
+func (d *Deque[T]) Clear() {
+	var zero T
+	for i := 0; i < d.n; i++ {
+		d.buf[(d.head+i)&d.mask()] = zero
+	}
+	d.head, d.n = 0, 0
+}
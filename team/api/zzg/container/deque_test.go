@@ -0,0 +1,185 @@
+package main
+
+import (
+	"container/list"
+	"container/ring"
+	"math/rand"
+	"testing"
+)
+
+func dequeSlice(d *Deque[int]) []int {
+	out := make([]int, d.Len())
+	for i := range out {
+		out[i] = d.At(i)
+	}
+	return out
+}
+
+func listSlice(l *list.List) []int {
+	var out []int
+	for e := l.Front(); e != nil; e = e.Next() {
+		out = append(out, e.Value.(int))
+	}
+	return out
+}
+
+// 随机操作后与 list.List 的结果对比
+func TestDequeMatchesList(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var d Deque[int]
+	l := list.New()
+	for i := 0; i < 10000; i++ {
+		switch r.Intn(6) {
+		case 0:
+			d.PushBack(i)
+			l.PushBack(i)
+		case 1:
+			d.PushFront(i)
+			l.PushFront(i)
+		case 2:
+			x, ok := d.PopFront()
+			if e := l.Front(); e == nil {
+				if ok {
+					t.Fatal("PopFront on empty deque returned true")
+				}
+			} else if !ok || x != l.Remove(e).(int) {
+				t.Fatalf("PopFront = %d, %v", x, ok)
+			}
+		case 3:
+			x, ok := d.PopBack()
+			if e := l.Back(); e == nil {
+				if ok {
+					t.Fatal("PopBack on empty deque returned true")
+				}
+			} else if !ok || x != l.Remove(e).(int) {
+				t.Fatalf("PopBack = %d, %v", x, ok)
+			}
+		case 4:
+			if d.Len() > 0 {
+				j := r.Intn(d.Len())
+				d.Set(j, -i)
+				e := l.Front()
+				for k := 0; k < j; k++ {
+					e = e.Next()
+				}
+				e.Value = -i
+			}
+		case 5:
+			n := r.Intn(21) - 10
+			d.Rotate(n)
+			for k := 0; l.Len() > 0 && k < (n%l.Len()+l.Len())%l.Len(); k++ {
+				l.MoveToBack(l.Front())
+			}
+		}
+		if d.Len() != l.Len() {
+			t.Fatalf("step %d: Len = %d, want %d", i, d.Len(), l.Len())
+		}
+	}
+	got, want := dequeSlice(&d), listSlice(l)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("deque = %v\nlist  = %v", got, want)
+		}
+	}
+}
+
+// Rotate 与 ring.Move 的方向一致
+func TestDequeRotateLikeRing(t *testing.T) {
+	for n := -7; n <= 7; n++ {
+		var d Deque[int]
+		r := ring.New(5)
+		for i := 0; i < 5; i++ {
+			d.PushBack(i)
+			r.Value = i
+			r = r.Next()
+		}
+		d.Rotate(n)
+		r = r.Move(n)
+		for i := 0; i < 5; i++ {
+			if d.At(i) != r.Value.(int) {
+				t.Fatalf("Rotate(%d): At(%d) = %d, want %d", n, i, d.At(i), r.Value)
+			}
+			r = r.Next()
+		}
+	}
+}
+
+func TestDequeEmpty(t *testing.T) {
+	var d Deque[string]
+	if _, ok := d.Front(); ok {
+		t.Error("Front on empty deque")
+	}
+	if _, ok := d.Back(); ok {
+		t.Error("Back on empty deque")
+	}
+	d.PushFront("a")
+	d.PushBack("b")
+	if x, _ := d.Front(); x != "a" {
+		t.Errorf("Front = %q", x)
+	}
+	if x, _ := d.Back(); x != "b" {
+		t.Errorf("Back = %q", x)
+	}
+	d.Clear()
+	if d.Len() != 0 {
+		t.Errorf("Len after Clear = %d", d.Len())
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("At out of range did not panic")
+		}
+	}()
+	d.At(0)
+}
+
+func BenchmarkDequePushPop(b *testing.B) {
+	var d Deque[int]
+	for i := 0; i < b.N; i++ {
+		d.PushBack(i)
+		d.PushFront(i)
+		if d.Len() > 1024 {
+			d.PopFront()
+			d.PopBack()
+		}
+	}
+}
+
+func BenchmarkListPushPop(b *testing.B) {
+	l := list.New()
+	for i := 0; i < b.N; i++ {
+		l.PushBack(i)
+		l.PushFront(i)
+		if l.Len() > 1024 {
+			l.Remove(l.Front())
+			l.Remove(l.Back())
+		}
+	}
+}
+
+func BenchmarkDequeIterate(b *testing.B) {
+	var d Deque[int]
+	for i := 0; i < 1024; i++ {
+		d.PushBack(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := 0
+		for j := 0; j < d.Len(); j++ {
+			sum += d.At(j)
+		}
+	}
+}
+
+func BenchmarkListIterate(b *testing.B) {
+	l := list.New()
+	for i := 0; i < 1024; i++ {
+		l.PushBack(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		sum := 0
+		for e := l.Front(); e != nil; e = e.Next() {
+			sum += e.Value.(int)
+		}
+	}
+}
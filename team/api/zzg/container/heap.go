@@ -0,0 +1,135 @@
+package main
+
+// Heap 是泛型的二叉堆，less(a, b) 为 true 时 a 排在 b 前面（less 用 < 即为最小堆）。
+// 与 container/heap 的算法相同，但不需要为每种类型实现5个接口，
+// 也没有 interface{} 装箱和类型断言的开销。零值不可用，请用 NewHeap 或 NewIndexedHeap 创建
+type Heap[T any] struct {
+	data     []T
+	less     func(a, b T) bool
+	setIndex func(x T, i int)
+}
+
+// NewHeap 用 items 建堆，复杂度 O(n)，items 的底层数组会被堆直接使用
+func NewHeap[T any](less func(a, b T) bool, items ...T) *Heap[T] {
+	return NewIndexedHeap(less, nil, items...)
+}
+
+// NewIndexedHeap 同 NewHeap，元素在堆中的下标变化时调用 setIndex(x, i)，
+// 元素被 Pop/Remove 移出堆时 i 为 -1。调用方据此记下每个元素的下标，
+// 之后就可以对任意元素调用 Fix/Remove（例如实现 decrease-key）
+func NewIndexedHeap[T any](less func(a, b T) bool, setIndex func(x T, i int), items ...T) *Heap[T] {
+	h := &Heap[T]{data: items, less: less, setIndex: setIndex}
+	if setIndex != nil {
+		for i, x := range items {
+			setIndex(x, i)
+		}
+	}
+	n := len(h.data)
+	for i := n/2 - 1; i >= 0; i-- {
+		h.down(i, n)
+	}
+	return h
+}
+
+// Len 返回长度
+func (h *Heap[T]) Len() int {
+	return len(h.data)
+}
+
+// Push 压入数据，复杂度 O(log n)
+func (h *Heap[T]) Push(x T) {
+	h.data = append(h.data, x)
+	if h.setIndex != nil {
+		h.setIndex(x, len(h.data)-1)
+	}
+	h.up(len(h.data) - 1)
+}
+
+// Pop 弹出堆顶，堆为空时 panic（与 container/heap 一致）
+func (h *Heap[T]) Pop() T {
+	n := len(h.data) - 1
+	h.swap(0, n)
+	h.down(0, n)
+	return h.shrink()
+}
+
+// Peek 查看堆顶但不弹出，堆为空时 panic
+func (h *Heap[T]) Peek() T {
+	return h.data[0]
+}
+
+// At 返回下标 i 处的元素，At(0) 即堆顶
+func (h *Heap[T]) At(i int) T {
+	return h.data[i]
+}
+
+// Remove 删除并返回下标 i 处的元素
+func (h *Heap[T]) Remove(i int) T {
+	n := len(h.data) - 1
+	if n != i {
+		h.swap(i, n)
+		if !h.down(i, n) {
+			h.up(i)
+		}
+	}
+	return h.shrink()
+}
+
+// Fix 在下标 i 处的元素的排序依据被修改后调用，恢复堆的结构
+func (h *Heap[T]) Fix(i int) {
+	if !h.down(i, len(h.data)) {
+		h.up(i)
+	}
+}
+
+// shrink 去掉并返回最后一个元素，清零防止残留引用
+func (h *Heap[T]) shrink() T {
+	n := len(h.data) - 1
+	x := h.data[n]
+	var zero T
+	h.data[n] = zero
+	h.data = h.data[:n]
+	if h.setIndex != nil {
+		h.setIndex(x, -1)
+	}
+	return x
+}
+
+func (h *Heap[T]) swap(i, j int) {
+	h.data[i], h.data[j] = h.data[j], h.data[i]
+	if h.setIndex != nil {
+		h.setIndex(h.data[i], i)
+		h.setIndex(h.data[j], j)
+	}
+}
+
+func (h *Heap[T]) up(j int) {
+	for {
+		i := (j - 1) / 2 // parent
+		if i == j || !h.less(h.data[j], h.data[i]) {
+			break
+		}
+		h.swap(i, j)
+		j = i
+	}
+}
+
+func (h *Heap[T]) down(i0, n int) bool {
+	i := i0
+	for {
+		j1 := 2*i + 1
+		if j1 >= n || j1 < 0 { // j1 < 0 after int overflow
+			break
+		}
+		j := j1 // left child
+		if j2 := j1 + 1; j2 < n && h.less(h.data[j2], h.data[j1]) {
+			j = j2 // = 2*i + 2  // right child
+		}
+		if !h.less(h.data[j], h.data[i]) {
+			break
+		}
+		h.swap(i, j)
+		i = j
+	}
+	return i > i0
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"container/heap"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func intLess(a, b int) bool { return a < b }
+
+// items 按下标顺序返回堆中的全部元素
+func items[T any](h *Heap[T]) []T {
+	out := make([]T, h.Len())
+	for i := range out {
+		out[i] = h.At(i)
+	}
+	return out
+}
+
+// 按 main 里 IntHeap 的例子操作，结果应与 container/heap 完全一致
+func TestHeapMatchesIntHeap(t *testing.T) {
+	a := IntHeap{6, 2, 3, 1, 5, 4}
+	heap.Init(&a)
+	h := NewHeap(intLess, 6, 2, 3, 1, 5, 4)
+	if got := items(h); !reflect.DeepEqual([]int(a), got) {
+		t.Fatalf("init: %v, want %v", got, a)
+	}
+
+	for i := 0; i < 2; i++ {
+		want, got := heap.Pop(&a).(int), h.Pop()
+		if got != want {
+			t.Errorf("Pop = %d, want %d", got, want)
+		}
+	}
+	heap.Push(&a, 0)
+	heap.Push(&a, 8)
+	h.Push(0)
+	h.Push(8)
+	if got := items(h); !reflect.DeepEqual([]int(a), got) {
+		t.Errorf("after push: %v, want %v", got, a)
+	}
+	if h.Peek() != 0 {
+		t.Errorf("Peek = %d, want 0", h.Peek())
+	}
+}
+
+// indexed 通过 setIndex 记录自己在堆中的下标
+type indexed struct {
+	v, index int
+}
+
+func TestHeapFixRemove(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := IntHeap{}
+	h := NewIndexedHeap(
+		func(x, y *indexed) bool { return x.v < y.v },
+		func(x *indexed, i int) { x.index = i },
+	)
+	var all []*indexed
+	for i := 0; i < 200; i++ {
+		x := &indexed{v: r.Intn(1000)}
+		all = append(all, x)
+		heap.Push(&a, x.v)
+		h.Push(x)
+	}
+	for i := 0; i < 50; i++ {
+		// 随机挑一个还在堆中的元素，按它记录的下标修改或删除
+		x := all[r.Intn(len(all))]
+		if x.index < 0 {
+			continue
+		}
+		if h.At(x.index) != x {
+			t.Fatalf("At(%d) is not the element indexed there", x.index)
+		}
+		j := x.index
+		switch i % 2 {
+		case 0:
+			x.v = r.Intn(1000)
+			a[j] = x.v
+			heap.Fix(&a, j)
+			h.Fix(j)
+		case 1:
+			if got, want := h.Remove(j), heap.Remove(&a, j).(int); got != x || got.v != want {
+				t.Fatalf("Remove(%d) = %d, want %d", j, got.v, want)
+			}
+			if x.index != -1 {
+				t.Fatalf("removed element has index %d, want -1", x.index)
+			}
+		}
+	}
+	prev := -1
+	for h.Len() > 0 {
+		got, want := h.Pop(), heap.Pop(&a).(int)
+		if got.v != want || got.v < prev || got.index != -1 {
+			t.Fatalf("Pop = %d (index %d), want %d (prev %d)", got.v, got.index, want, prev)
+		}
+		prev = got.v
+	}
+}
+
+func BenchmarkIntHeap(b *testing.B) {
+	h := &IntHeap{}
+	for i := 0; i < b.N; i++ {
+		heap.Push(h, i%1024)
+		if h.Len() > 512 {
+			heap.Pop(h)
+		}
+	}
+}
+
+func BenchmarkGenericHeap(b *testing.B) {
+	h := NewHeap[int](intLess)
+	for i := 0; i < b.N; i++ {
+		h.Push(i % 1024)
+		if h.Len() > 512 {
+			h.Pop()
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"cmp"
+)
+
+// Interval 是闭区间 [Lo, Hi] 及其附带的值
+type Interval[K cmp.Ordered, V any] struct {
+	Lo, Hi K
+	Value  V
+}
+
+type itNode[K cmp.Ordered, V any] struct {
+	iv          Interval[K, V]
+	max         K // 子树中最大的 Hi
+	height      int
+	left, right *itNode[K, V]
+}
+
+// IntervalTree 是区间树：按 Lo 排序的 AVL 树，每个节点额外记录子树中最大的 Hi，
+// 查询时可以跳过不可能重叠的子树。Insert/Delete 为 O(log n)，
+// Overlapping 最坏为 O(min(n, m·log n))，m 为结果个数。零值可以直接使用，非并发安全
+type IntervalTree[K cmp.Ordered, V any] struct {
+	root *itNode[K, V]
+	n    int
+}
+
+// Len 返回区间个数
+func (t *IntervalTree[K, V]) Len() int {
+	return t.n
+}
+
+// Insert 加入区间 [lo, hi]，允许重复，lo > hi 时 panic
+func (t *IntervalTree[K, V]) Insert(lo, hi K, v V) {
+	if lo > hi {
+		panic("container: interval lo > hi")
+	}
+	t.root = t.root.insert(Interval[K, V]{lo, hi, v})
+	t.n++
+}
+
+// Delete 删除一个边界为 [lo, hi] 的区间，有多个相同边界的区间时只删除其中一个
+func (t *IntervalTree[K, V]) Delete(lo, hi K) bool {
+	var ok bool
+	t.root, ok = t.root.delete(lo, hi)
+	if ok {
+		t.n--
+	}
+	return ok
+}
+
+// Overlapping 按 Lo 从小到大返回所有与 [lo, hi] 有交集的区间
+func (t *IntervalTree[K, V]) Overlapping(lo, hi K) []Interval[K, V] {
+	var out []Interval[K, V]
+	t.root.overlapping(lo, hi, &out)
+	return out
+}
+
+// Stabbing 按 Lo 从小到大返回所有包含点 p 的区间
+func (t *IntervalTree[K, V]) Stabbing(p K) []Interval[K, V] {
+	return t.Overlapping(p, p)
+}
+
+func (n *itNode[K, V]) h() int {
+	if n == nil {
+		return 0
+	}
+	return n.height
+}
+
+// update 重新计算高度和 max
+func (n *itNode[K, V]) update() {
+	n.height = 1 + max(n.left.h(), n.right.h())
+	n.max = n.iv.Hi
+	if n.left != nil {
+		n.max = max(n.max, n.left.max)
+	}
+	if n.right != nil {
+		n.max = max(n.max, n.right.max)
+	}
+}
+
+func (n *itNode[K, V]) rotateRight() *itNode[K, V] {
+	l := n.left
+	n.left = l.right
+	l.right = n
+	n.update()
+	l.update()
+	return l
+}
+
+func (n *itNode[K, V]) rotateLeft() *itNode[K, V] {
+	r := n.right
+	n.right = r.left
+	r.left = n
+	n.update()
+	r.update()
+	return r
+}
+
+// balance 更新节点并在左右高度差超过 1 时旋转
+func (n *itNode[K, V]) balance() *itNode[K, V] {
+	n.update()
+	switch bf := n.left.h() - n.right.h(); {
+	case bf > 1:
+		if n.left.left.h() < n.left.right.h() {
+			n.left = n.left.rotateLeft()
+		}
+		return n.rotateRight()
+	case bf < -1:
+		if n.right.right.h() < n.right.left.h() {
+			n.right = n.right.rotateRight()
+		}
+		return n.rotateLeft()
+	}
+	return n
+}
+
+// ivLess 按 (Lo, Hi) 排序
+func ivLess[K cmp.Ordered](lo1, hi1, lo2, hi2 K) bool {
+	return lo1 < lo2 || lo1 == lo2 && hi1 < hi2
+}
+
+func (n *itNode[K, V]) insert(iv Interval[K, V]) *itNode[K, V] {
+	if n == nil {
+		return &itNode[K, V]{iv: iv, max: iv.Hi, height: 1}
+	}
+	if ivLess(iv.Lo, iv.Hi, n.iv.Lo, n.iv.Hi) {
+		n.left = n.left.insert(iv)
+	} else {
+		n.right = n.right.insert(iv)
+	}
+	return n.balance()
+}
+
+func (n *itNode[K, V]) delete(lo, hi K) (*itNode[K, V], bool) {
+	if n == nil {
+		return nil, false
+	}
+	var ok bool
+	switch {
+	case ivLess(lo, hi, n.iv.Lo, n.iv.Hi):
+		n.left, ok = n.left.delete(lo, hi)
+	case ivLess(n.iv.Lo, n.iv.Hi, lo, hi):
+		n.right, ok = n.right.delete(lo, hi)
+	default:
+		if n.left == nil {
+			return n.right, true
+		}
+		if n.right == nil {
+			return n.left, true
+		}
+		// 用右子树的最小节点替换当前节点
+		var m *itNode[K, V]
+		n.right, m = n.right.deleteMin()
+		n.iv = m.iv
+		ok = true
+	}
+	if !ok {
+		return n, false
+	}
+	return n.balance(), true
+}
+
+func (n *itNode[K, V]) deleteMin() (*itNode[K, V], *itNode[K, V]) {
+	if n.left == nil {
+		return n.right, n
+	}
+	var m *itNode[K, V]
+	n.left, m = n.left.deleteMin()
+	return n.balance(), m
+}
+
+func (n *itNode[K, V]) overlapping(lo, hi K, out *[]Interval[K, V]) {
+	if n == nil || n.max < lo {
+		return
+	}
+	n.left.overlapping(lo, hi, out)
+	if n.iv.Lo > hi {
+		// 右子树的 Lo 都不小于当前节点，不可能重叠
+		return
+	}
+	if n.iv.Hi >= lo {
+		*out = append(*out, n.iv)
+	}
+	n.right.overlapping(lo, hi, out)
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/quick"
+)
+
+// bruteIntervals 是用切片逐个比较的参照实现
+type bruteIntervals []Interval[int, int]
+
+func (b bruteIntervals) overlapping(lo, hi int) []Interval[int, int] {
+	var out []Interval[int, int]
+	for _, iv := range b {
+		if iv.Lo <= hi && iv.Hi >= lo {
+			out = append(out, iv)
+		}
+	}
+	return out
+}
+
+func containsBounds(b bruteIntervals, lo, hi int) bool {
+	for _, iv := range b {
+		if iv.Lo == lo && iv.Hi == hi {
+			return true
+		}
+	}
+	return false
+}
+
+func sortIntervals(ivs []Interval[int, int]) {
+	sort.Slice(ivs, func(i, j int) bool {
+		a, b := ivs[i], ivs[j]
+		if a.Lo != b.Lo {
+			return a.Lo < b.Lo
+		}
+		if a.Hi != b.Hi {
+			return a.Hi < b.Hi
+		}
+		return a.Value < b.Value
+	})
+}
+
+// checkTree 检查 AVL 平衡和 max 字段
+func checkTree(t *testing.T, n *itNode[int, int]) (height, maxHi int) {
+	if n == nil {
+		return 0, -1 << 31
+	}
+	lh, lm := checkTree(t, n.left)
+	rh, rm := checkTree(t, n.right)
+	if d := lh - rh; d > 1 || d < -1 {
+		t.Fatalf("unbalanced node [%d,%d]: %d vs %d", n.iv.Lo, n.iv.Hi, lh, rh)
+	}
+	if want := max(n.iv.Hi, lm, rm); n.max != want {
+		t.Fatalf("node [%d,%d]: max = %d, want %d", n.iv.Lo, n.iv.Hi, n.max, want)
+	}
+	return 1 + max(lh, rh), n.max
+}
+
+// 随机插入删除后与参照实现对比查询结果
+func TestIntervalTreeProperty(t *testing.T) {
+	f := func(seed int64) bool {
+		r := rand.New(rand.NewSource(seed))
+		var tree IntervalTree[int, int]
+		var ref bruteIntervals
+		for i := 0; i < 300; i++ {
+			if len(ref) > 0 && r.Intn(3) == 0 {
+				j := r.Intn(len(ref))
+				if !tree.Delete(ref[j].Lo, ref[j].Hi) {
+					t.Logf("Delete(%d, %d) = false", ref[j].Lo, ref[j].Hi)
+					return false
+				}
+				ref = append(ref[:j], ref[j+1:]...)
+				continue
+			}
+			lo := r.Intn(1000)
+			hi := lo + r.Intn(100)
+			if containsBounds(ref, lo, hi) {
+				continue // 相同边界时删掉哪一个不确定，随机测试里不生成重复区间
+			}
+			tree.Insert(lo, hi, i)
+			ref = append(ref, Interval[int, int]{lo, hi, i})
+		}
+		checkTree(t, tree.root)
+		if tree.Len() != len(ref) {
+			t.Logf("Len = %d, want %d", tree.Len(), len(ref))
+			return false
+		}
+		for q := 0; q < 100; q++ {
+			lo := r.Intn(1100) - 50
+			hi := lo + r.Intn(50)
+			got, want := tree.Overlapping(lo, hi), ref.overlapping(lo, hi)
+			sortIntervals(got)
+			sortIntervals(want)
+			if len(got)+len(want) > 0 && !reflect.DeepEqual(got, want) {
+				t.Logf("Overlapping(%d, %d) = %v, want %v", lo, hi, got, want)
+				return false
+			}
+		}
+		return true
+	}
+	if err := quick.Check(f, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestIntervalTreeStabbing(t *testing.T) {
+	var tree IntervalTree[int, string]
+	tree.Insert(9, 12, "morning")
+	tree.Insert(11, 14, "lunch")
+	tree.Insert(13, 18, "afternoon")
+	tree.Insert(20, 22, "evening")
+
+	var got []string
+	for _, iv := range tree.Stabbing(13) {
+		got = append(got, iv.Value)
+	}
+	if want := []string{"lunch", "afternoon"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Stabbing(13) = %v, want %v", got, want)
+	}
+	if got := tree.Stabbing(19); len(got) != 0 {
+		t.Errorf("Stabbing(19) = %v, want none", got)
+	}
+	if tree.Delete(9, 13) {
+		t.Error("Delete of a missing interval returned true")
+	}
+	if !tree.Delete(11, 14) || len(tree.Overlapping(12, 12)) != 1 {
+		t.Error("Delete(11, 14) did not remove the interval")
+	}
+	tree.Insert(20, 22, "party")
+	if !tree.Delete(20, 22) || len(tree.Stabbing(21)) != 1 || tree.Len() != 3 {
+		t.Error("Delete should remove exactly one of the duplicate intervals")
+	}
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"cmp"
+	"iter"
+	"math/rand/v2"
+)
+
+/*
+	OrderedMap 是按键有序的 map，用跳表实现，不需要像 expvar 那样每次插入后 sort.Strings(varKeys)：
+		1.Get/Put/Delete 期望复杂度 O(log n)；
+		2.Floor/Ceiling 查找不大于/不小于某个键的最近元素；
+		3.每层指针记录跨过的元素个数（span），Rank/At 按名次查找也是 O(log n)；
+		4.All/Keys/Ascend 返回 iter.Seq2/iter.Seq，可以直接 for k, v := range m.All()。
+	SortedSet 是只有键的 OrderedMap。非并发安全。
+*/
+
+const (
+	skipMaxLevel = 32
+	skipP        = 4 // 每层晋升的概率为 1/skipP
+)
+
+type skipNode[K, V any] struct {
+	key  K
+	val  V
+	next []*skipNode[K, V]
+	span []int // span[i] 为沿第 i 层指针前进时跨过的元素个数
+}
+
+// OrderedMap 是按键有序的 map，零值不可用，请用 NewOrderedMap 或 NewOrderedMapFunc 创建
+type OrderedMap[K, V any] struct {
+	cmp    func(a, b K) int
+	head   *skipNode[K, V]
+	level  int
+	length int
+}
+
+// NewOrderedMap 创建按 cmp.Compare 排序的有序 map
+func NewOrderedMap[K cmp.Ordered, V any]() *OrderedMap[K, V] {
+	return NewOrderedMapFunc[K, V](cmp.Compare[K])
+}
+
+// NewOrderedMapFunc 创建按 compare 排序的有序 map，compare 的约定同 cmp.Compare
+func NewOrderedMapFunc[K, V any](compare func(a, b K) int) *OrderedMap[K, V] {
+	return &OrderedMap[K, V]{
+		cmp:   compare,
+		head:  &skipNode[K, V]{next: make([]*skipNode[K, V], skipMaxLevel), span: make([]int, skipMaxLevel)},
+		level: 1,
+	}
+}
+
+func randomLevel() int {
+	lvl := 1
+	for lvl < skipMaxLevel && rand.N(skipP) == 0 {
+		lvl++
+	}
+	return lvl
+}
+
+// Len 返回元素个数
+func (m *OrderedMap[K, V]) Len() int {
+	return m.length
+}
+
+// search 返回每一层中最后一个键小于 key 的节点，以及这些节点的名次
+func (m *OrderedMap[K, V]) search(key K, update *[skipMaxLevel]*skipNode[K, V], rank *[skipMaxLevel]int) *skipNode[K, V] {
+	x := m.head
+	r := 0
+	for i := m.level - 1; i >= 0; i-- {
+		for x.next[i] != nil && m.cmp(x.next[i].key, key) < 0 {
+			r += x.span[i]
+			x = x.next[i]
+		}
+		if update != nil {
+			update[i] = x
+		}
+		if rank != nil {
+			rank[i] = r
+		}
+	}
+	return x
+}
+
+// Get 返回 key 对应的值
+func (m *OrderedMap[K, V]) Get(key K) (V, bool) {
+	if n := m.search(key, nil, nil).next[0]; n != nil && m.cmp(n.key, key) == 0 {
+		return n.val, true
+	}
+	var zero V
+	return zero, false
+}
+
+// Put 写入键值，key 已存在时覆盖并返回 true
+func (m *OrderedMap[K, V]) Put(key K, val V) bool {
+	var update [skipMaxLevel]*skipNode[K, V]
+	var rank [skipMaxLevel]int
+	x := m.search(key, &update, &rank)
+	if n := x.next[0]; n != nil && m.cmp(n.key, key) == 0 {
+		n.val = val
+		return true
+	}
+	lvl := randomLevel()
+	for i := m.level; i < lvl; i++ {
+		update[i], rank[i] = m.head, 0
+		m.head.span[i] = m.length
+	}
+	if lvl > m.level {
+		m.level = lvl
+	}
+	n := &skipNode[K, V]{key: key, val: val, next: make([]*skipNode[K, V], lvl), span: make([]int, lvl)}
+	for i := 0; i < lvl; i++ {
+		n.next[i] = update[i].next[i]
+		update[i].next[i] = n
+		n.span[i] = update[i].span[i] - (rank[0] - rank[i])
+		update[i].span[i] = rank[0] - rank[i] + 1
+	}
+	for i := lvl; i < m.level; i++ {
+		update[i].span[i]++
+	}
+	m.length++
+	return false
+}
+
+// Delete 删除 key，返回 key 是否存在
+func (m *OrderedMap[K, V]) Delete(key K) bool {
+	var update [skipMaxLevel]*skipNode[K, V]
+	n := m.search(key, &update, nil).next[0]
+	if n == nil || m.cmp(n.key, key) != 0 {
+		return false
+	}
+	for i := 0; i < m.level; i++ {
+		if update[i].next[i] == n {
+			update[i].span[i] += n.span[i] - 1
+			update[i].next[i] = n.next[i]
+		} else {
+			update[i].span[i]--
+		}
+	}
+	for m.level > 1 && m.head.next[m.level-1] == nil {
+		m.level--
+	}
+	m.length--
+	return true
+}
+
+// Floor 返回不大于 key 的最大元素
+func (m *OrderedMap[K, V]) Floor(key K) (K, V, bool) {
+	x := m.search(key, nil, nil)
+	if n := x.next[0]; n != nil && m.cmp(n.key, key) == 0 {
+		return n.key, n.val, true
+	}
+	if x == m.head {
+		var k K
+		var v V
+		return k, v, false
+	}
+	return x.key, x.val, true
+}
+
+// Ceiling 返回不小于 key 的最小元素
+func (m *OrderedMap[K, V]) Ceiling(key K) (K, V, bool) {
+	if n := m.search(key, nil, nil).next[0]; n != nil {
+		return n.key, n.val, true
+	}
+	var k K
+	var v V
+	return k, v, false
+}
+
+// Rank 返回小于 key 的元素个数，key 存在时即为它从 0 开始的名次
+func (m *OrderedMap[K, V]) Rank(key K) int {
+	var rank [skipMaxLevel]int
+	m.search(key, nil, &rank)
+	return rank[0]
+}
+
+// At 返回按键排序后第 i 个（从 0 开始）元素
+func (m *OrderedMap[K, V]) At(i int) (K, V, bool) {
+	if i < 0 || i >= m.length {
+		var k K
+		var v V
+		return k, v, false
+	}
+	x := m.head
+	traversed := 0
+	for l := m.level - 1; l >= 0; l-- {
+		for x.next[l] != nil && traversed+x.span[l] <= i+1 {
+			traversed += x.span[l]
+			x = x.next[l]
+		}
+		if traversed == i+1 {
+			break
+		}
+	}
+	return x.key, x.val, true
+}
+
+// All 按键从小到大遍历所有元素，遍历期间不要修改
+func (m *OrderedMap[K, V]) All() iter.Seq2[K, V] {
+	return m.from(m.head.next[0], nil)
+}
+
+// Keys 按从小到大的顺序遍历所有键
+func (m *OrderedMap[K, V]) Keys() iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for n := m.head.next[0]; n != nil; n = n.next[0] {
+			if !yield(n.key) {
+				return
+			}
+		}
+	}
+}
+
+// Ascend 按键从小到大遍历 [from, to) 范围内的元素
+func (m *OrderedMap[K, V]) Ascend(from, to K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		m.from(m.search(from, nil, nil).next[0], &to)(yield)
+	}
+}
+
+func (m *OrderedMap[K, V]) from(start *skipNode[K, V], to *K) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for n := start; n != nil; n = n.next[0] {
+			if to != nil && m.cmp(n.key, *to) >= 0 {
+				return
+			}
+			if !yield(n.key, n.val) {
+				return
+			}
+		}
+	}
+}
+
+// SortedSet 是有序集合
+type SortedSet[K any] struct {
+	m *OrderedMap[K, struct{}]
+}
+
+// NewSortedSet 创建按 cmp.Compare 排序的有序集合
+func NewSortedSet[K cmp.Ordered]() *SortedSet[K] {
+	return &SortedSet[K]{m: NewOrderedMap[K, struct{}]()}
+}
+
+// NewSortedSetFunc 创建按 compare 排序的有序集合
+func NewSortedSetFunc[K any](compare func(a, b K) int) *SortedSet[K] {
+	return &SortedSet[K]{m: NewOrderedMapFunc[K, struct{}](compare)}
+}
+
+// Add 加入 key，返回 key 原来是否已存在
+func (s *SortedSet[K]) Add(key K) bool { return s.m.Put(key, struct{}{}) }
+
+// Remove 删除 key，返回 key 是否存在
+func (s *SortedSet[K]) Remove(key K) bool { return s.m.Delete(key) }
+
+// Contains 返回 key 是否存在
+func (s *SortedSet[K]) Contains(key K) bool {
+	_, ok := s.m.Get(key)
+	return ok
+}
+
+// Len 返回元素个数
+func (s *SortedSet[K]) Len() int { return s.m.Len() }
+
+// Rank 返回小于 key 的元素个数
+func (s *SortedSet[K]) Rank(key K) int { return s.m.Rank(key) }
+
+// At 返回第 i 个（从 0 开始）元素
+func (s *SortedSet[K]) At(i int) (K, bool) {
+	k, _, ok := s.m.At(i)
+	return k, ok
+}
+
+// Floor 返回不大于 key 的最大元素
+func (s *SortedSet[K]) Floor(key K) (K, bool) {
+	k, _, ok := s.m.Floor(key)
+	return k, ok
+}
+
+// Ceiling 返回不小于 key 的最小元素
+func (s *SortedSet[K]) Ceiling(key K) (K, bool) {
+	k, _, ok := s.m.Ceiling(key)
+	return k, ok
+}
+
+// All 按从小到大的顺序遍历所有元素
+func (s *SortedSet[K]) All() iter.Seq[K] { return s.m.Keys() }
+
+// Ascend 按从小到大的顺序遍历 [from, to) 范围内的元素
+func (s *SortedSet[K]) Ascend(from, to K) iter.Seq[K] {
+	return func(yield func(K) bool) {
+		for k := range s.m.Ascend(from, to) {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
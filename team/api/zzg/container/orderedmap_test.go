@@ -0,0 +1,129 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"slices"
+	"sort"
+	"testing"
+)
+
+// 随机操作后与 map + sort 的结果对比
+func TestOrderedMapRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	m := NewOrderedMap[int, int]()
+	ref := map[int]int{}
+	for i := 0; i < 5000; i++ {
+		k := r.Intn(500)
+		switch r.Intn(3) {
+		case 0, 1:
+			_, existed := ref[k]
+			if got := m.Put(k, i); got != existed {
+				t.Fatalf("Put(%d) = %v, want %v", k, got, existed)
+			}
+			ref[k] = i
+		case 2:
+			_, existed := ref[k]
+			if got := m.Delete(k); got != existed {
+				t.Fatalf("Delete(%d) = %v, want %v", k, got, existed)
+			}
+			delete(ref, k)
+		}
+	}
+
+	keys := make([]int, 0, len(ref))
+	for k := range ref {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	if m.Len() != len(keys) {
+		t.Fatalf("Len = %d, want %d", m.Len(), len(keys))
+	}
+	if got := slices.Collect(m.Keys()); !reflect.DeepEqual(got, keys) {
+		t.Fatalf("Keys = %v, want %v", got, keys)
+	}
+	for i, k := range keys {
+		if v, ok := m.Get(k); !ok || v != ref[k] {
+			t.Fatalf("Get(%d) = %d, %v, want %d", k, v, ok, ref[k])
+		}
+		if got := m.Rank(k); got != i {
+			t.Fatalf("Rank(%d) = %d, want %d", k, got, i)
+		}
+		if got, _, _ := m.At(i); got != k {
+			t.Fatalf("At(%d) = %d, want %d", i, got, k)
+		}
+	}
+	for q := -1; q <= 501; q++ {
+		i := sort.SearchInts(keys, q)
+		k, _, ok := m.Ceiling(q)
+		if wantOK := i < len(keys); ok != wantOK || ok && k != keys[i] {
+			t.Fatalf("Ceiling(%d) = %d, %v", q, k, ok)
+		}
+		if i < len(keys) && keys[i] == q {
+			i++
+		}
+		k, _, ok = m.Floor(q)
+		if wantOK := i > 0; ok != wantOK || ok && k != keys[i-1] {
+			t.Fatalf("Floor(%d) = %d, %v", q, k, ok)
+		}
+	}
+}
+
+func TestOrderedMapAscend(t *testing.T) {
+	m := NewOrderedMap[string, int]()
+	for i, k := range []string{"memstats", "cmdline", "requests", "errors", "goroutines"} {
+		m.Put(k, i)
+	}
+	var got []string
+	for k, v := range m.Ascend("d", "n") {
+		if v < 0 {
+			t.Fatal("unexpected value")
+		}
+		got = append(got, k)
+	}
+	if want := []string{"errors", "goroutines", "memstats"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ascend = %v, want %v", got, want)
+	}
+	got = got[:0]
+	for k := range m.All() {
+		got = append(got, k)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if want := []string{"cmdline", "errors"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("All with break = %v, want %v", got, want)
+	}
+	if _, _, ok := m.At(5); ok {
+		t.Error("At(Len) should fail")
+	}
+}
+
+func TestSortedSet(t *testing.T) {
+	s := NewSortedSetFunc(func(a, b int) int { return b - a }) // 从大到小
+	for _, x := range []int{3, 1, 4, 1, 5, 9, 2, 6} {
+		s.Add(x)
+	}
+	if s.Len() != 7 || !s.Contains(9) || s.Contains(7) {
+		t.Errorf("Len = %d", s.Len())
+	}
+	if got, want := slices.Collect(s.All()), []int{9, 6, 5, 4, 3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("All = %v, want %v", got, want)
+	}
+	if got, want := slices.Collect(s.Ascend(6, 2)), []int{6, 5, 4, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Ascend = %v, want %v", got, want)
+	}
+	if x, _ := s.Floor(7); x != 9 {
+		t.Errorf("Floor(7) = %d, want 9", x)
+	}
+	if x, _ := s.Ceiling(7); x != 6 {
+		t.Errorf("Ceiling(7) = %d, want 6", x)
+	}
+	if s.Rank(4) != 3 {
+		t.Errorf("Rank(4) = %d, want 3", s.Rank(4))
+	}
+	s.Remove(9)
+	if x, _ := s.At(0); x != 6 {
+		t.Errorf("At(0) = %d, want 6", x)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+// PQHandle 是元素在优先队列中的句柄，用来修改优先级或删除元素
+type PQHandle[T, P any] struct {
+	value    T
+	priority P
+	index    int    // 在堆中的下标，已出队时为 -1
+	seq      uint64 // 入队序号，优先级相同时先入队的先出
+}
+
+// Value 返回元素的值
+func (h *PQHandle[T, P]) Value() T {
+	return h.value
+}
+
+// Priority 返回元素当前的优先级
+func (h *PQHandle[T, P]) Priority() P {
+	return h.priority
+}
+
+// InQueue 返回元素是否还在队列中
+func (h *PQHandle[T, P]) InQueue() bool {
+	return h.index >= 0
+}
+
+// PriorityQueue 是带索引的优先队列：Push 返回句柄，之后可以通过句柄
+// 调整优先级（decrease-key）或删除元素，复杂度都是 O(log n)。
+// less(a, b) 为 true 时优先级 a 先出队，优先级相同时按入队顺序出队
+type PriorityQueue[T, P any] struct {
+	h   *Heap[*PQHandle[T, P]]
+	seq uint64
+}
+
+// NewPriorityQueue 创建优先队列
+func NewPriorityQueue[T, P any](less func(a, b P) bool) *PriorityQueue[T, P] {
+	return &PriorityQueue[T, P]{h: NewIndexedHeap(
+		func(a, b *PQHandle[T, P]) bool {
+			if less(a.priority, b.priority) {
+				return true
+			}
+			if less(b.priority, a.priority) {
+				return false
+			}
+			return a.seq < b.seq
+		},
+		func(h *PQHandle[T, P], i int) { h.index = i },
+	)}
+}
+
+// Len 返回队列长度
+func (pq *PriorityQueue[T, P]) Len() int {
+	return pq.h.Len()
+}
+
+// Push 以 priority 入队，返回元素的句柄
+func (pq *PriorityQueue[T, P]) Push(value T, priority P) *PQHandle[T, P] {
+	h := &PQHandle[T, P]{value: value, priority: priority, seq: pq.seq}
+	pq.seq++
+	pq.h.Push(h)
+	return h
+}
+
+// Peek 返回队首元素的句柄但不出队，队列为空时返回 nil
+func (pq *PriorityQueue[T, P]) Peek() *PQHandle[T, P] {
+	if pq.h.Len() == 0 {
+		return nil
+	}
+	return pq.h.Peek()
+}
+
+// Pop 出队并返回队首元素的句柄，队列为空时返回 nil
+func (pq *PriorityQueue[T, P]) Pop() *PQHandle[T, P] {
+	if pq.h.Len() == 0 {
+		return nil
+	}
+	return pq.h.Pop()
+}
+
+// Update 修改元素的优先级，元素已不在队列中时返回 false。
+// 元素保留原来的入队序号
+func (pq *PriorityQueue[T, P]) Update(h *PQHandle[T, P], priority P) bool {
+	if !pq.owns(h) {
+		return false
+	}
+	h.priority = priority
+	pq.h.Fix(h.index)
+	return true
+}
+
+// Remove 从队列中删除元素，元素已不在队列中时返回 false
+func (pq *PriorityQueue[T, P]) Remove(h *PQHandle[T, P]) bool {
+	if !pq.owns(h) {
+		return false
+	}
+	pq.h.Remove(h.index)
+	return true
+}
+
+func (pq *PriorityQueue[T, P]) owns(h *PQHandle[T, P]) bool {
+	return h != nil && h.index >= 0 && h.index < pq.h.Len() && pq.h.At(h.index) == h
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPriorityQueueStable(t *testing.T) {
+	pq := NewPriorityQueue[string](intLess)
+	pq.Push("a", 2)
+	pq.Push("b", 1)
+	pq.Push("c", 2)
+	d := pq.Push("d", 3)
+	pq.Push("e", 1)
+
+	if !pq.Update(d, 0) {
+		t.Fatal("Update returned false")
+	}
+	var got []string
+	for pq.Len() > 0 {
+		got = append(got, pq.Pop().Value())
+	}
+	if want := []string{"d", "b", "e", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+	if pq.Pop() != nil || pq.Peek() != nil {
+		t.Error("Pop/Peek on empty queue should return nil")
+	}
+	if d.InQueue() || pq.Update(d, 1) || pq.Remove(d) {
+		t.Error("popped handle should no longer be usable")
+	}
+}
+
+func TestPriorityQueueRemove(t *testing.T) {
+	pq := NewPriorityQueue[int](intLess)
+	hs := make([]*PQHandle[int, int], 10)
+	for i := range hs {
+		hs[i] = pq.Push(i, i)
+	}
+	for _, i := range []int{0, 5, 9} {
+		if !pq.Remove(hs[i]) {
+			t.Fatalf("Remove(%d) returned false", i)
+		}
+	}
+	other := NewPriorityQueue[int](intLess)
+	if other.Remove(hs[1]) {
+		t.Error("Remove of a handle from another queue should fail")
+	}
+	var got []int
+	for pq.Len() > 0 {
+		got = append(got, pq.Pop().Value())
+	}
+	if want := []int{1, 2, 3, 4, 6, 7, 8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+type edge struct {
+	to, w int
+}
+
+// dijkstra 用 PriorityQueue 的 Update 做 decrease-key
+func dijkstra(graph [][]edge, src int) []int {
+	dist := make([]int, len(graph))
+	pq := NewPriorityQueue[int](intLess)
+	hs := make([]*PQHandle[int, int], len(graph))
+	for v := range graph {
+		dist[v] = math.MaxInt
+		if v == src {
+			dist[v] = 0
+		}
+		hs[v] = pq.Push(v, dist[v])
+	}
+	for pq.Len() > 0 {
+		u := pq.Pop().Value()
+		if dist[u] == math.MaxInt {
+			break
+		}
+		for _, e := range graph[u] {
+			if d := dist[u] + e.w; d < dist[e.to] {
+				dist[e.to] = d
+				pq.Update(hs[e.to], d)
+			}
+		}
+	}
+	return dist
+}
+
+func TestDijkstra(t *testing.T) {
+	graph := [][]edge{
+		0: {{1, 7}, {2, 9}, {5, 14}},
+		1: {{0, 7}, {2, 10}, {3, 15}},
+		2: {{0, 9}, {1, 10}, {3, 11}, {5, 2}},
+		3: {{1, 15}, {2, 11}, {4, 6}},
+		4: {{3, 6}, {5, 9}},
+		5: {{0, 14}, {2, 2}, {4, 9}},
+		6: {},
+	}
+	got := dijkstra(graph, 0)
+	want := []int{0, 7, 9, 20, 20, 11, math.MaxInt}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("dijkstra = %v, want %v", got, want)
+	}
+}
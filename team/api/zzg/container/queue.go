@@ -0,0 +1,228 @@
+package main
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+/*
+	goroutine 之间传递数据的有界无锁队列，只用原子操作，没有互斥锁：
+		1.SPSCQueue 只允许一个生产者和一个消费者；
+		2.MPMCQueue 允许多个生产者和多个消费者（Dmitry Vyukov 的有界 MPMC 队列算法）。
+	TryEnqueue/TryDequeue 不阻塞，队列满/空时立即返回 false；
+	Enqueue/Dequeue 在队列满/空时自旋并让出 CPU，适合高吞吐、队列很少长时间空闲的流水线。
+	Close 与关闭 channel 的语义相同：必须在所有 Enqueue 返回之后调用，之后再 Enqueue 会 panic，
+	Dequeue 取完剩余元素后返回 false。
+*/
+
+const cacheLine = 64
+
+// pad 把频繁修改的计数器隔开，避免伪共享
+type pad [cacheLine - 8]byte
+
+// roundPow2 返回不小于 n 的 2 的幂，用掩码代替取模
+func roundPow2(n int) int {
+	if n <= 0 {
+		panic("container: queue capacity must be positive")
+	}
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// spin 是等待时的退避：先忙等几次，之后每次让出 CPU
+func spin(i int) {
+	if i < 16 {
+		return
+	}
+	runtime.Gosched()
+}
+
+// SPSCQueue 是单生产者单消费者的有界队列
+type SPSCQueue[T any] struct {
+	_      pad
+	head   atomic.Uint64 // 消费者读取的位置
+	_      pad
+	tail   atomic.Uint64 // 生产者写入的位置
+	_      pad
+	closed atomic.Bool
+	mask   uint64
+	buf    []T
+}
+
+// NewSPSCQueue 创建容量不小于 capacity 的队列，容量会向上取整为 2 的幂
+func NewSPSCQueue[T any](capacity int) *SPSCQueue[T] {
+	n := roundPow2(capacity)
+	return &SPSCQueue[T]{mask: uint64(n - 1), buf: make([]T, n)}
+}
+
+// TryEnqueue 入队，队列满时返回 false，只能由生产者调用
+func (q *SPSCQueue[T]) TryEnqueue(x T) bool {
+	if q.closed.Load() {
+		panic("container: enqueue on closed queue")
+	}
+	tail := q.tail.Load()
+	if tail-q.head.Load() > q.mask {
+		return false
+	}
+	q.buf[tail&q.mask] = x
+	q.tail.Store(tail + 1)
+	return true
+}
+
+// Enqueue 入队，队列满时等待，只能由生产者调用
+func (q *SPSCQueue[T]) Enqueue(x T) {
+	for i := 0; !q.TryEnqueue(x); i++ {
+		spin(i)
+	}
+}
+
+// TryDequeue 出队，队列空时返回 false，只能由消费者调用
+func (q *SPSCQueue[T]) TryDequeue() (T, bool) {
+	var zero T
+	head := q.head.Load()
+	if head == q.tail.Load() {
+		return zero, false
+	}
+	x := q.buf[head&q.mask]
+	q.buf[head&q.mask] = zero
+	q.head.Store(head + 1)
+	return x, true
+}
+
+// Dequeue 出队，队列空时等待；队列已关闭且取空后返回 false。只能由消费者调用
+func (q *SPSCQueue[T]) Dequeue() (T, bool) {
+	for i := 0; ; i++ {
+		if x, ok := q.TryDequeue(); ok {
+			return x, true
+		}
+		if q.closed.Load() {
+			// 关闭前的最后一次写入可能刚好发生在上面两次检查之间
+			return q.TryDequeue()
+		}
+		spin(i)
+	}
+}
+
+// Close 关闭队列
+func (q *SPSCQueue[T]) Close() {
+	q.closed.Store(true)
+}
+
+// Len 返回队列中的元素个数，并发读写时只是一个近似值
+func (q *SPSCQueue[T]) Len() int {
+	return int(q.tail.Load() - q.head.Load())
+}
+
+type mpmcCell[T any] struct {
+	seq atomic.Uint64
+	val T
+}
+
+// MPMCQueue 是多生产者多消费者的有界队列。
+// 每个槽位带一个序号，生产者和消费者通过 CAS 抢占位置，再用序号发布/回收槽位
+type MPMCQueue[T any] struct {
+	_      pad
+	head   atomic.Uint64
+	_      pad
+	tail   atomic.Uint64
+	_      pad
+	closed atomic.Bool
+	mask   uint64
+	cells  []mpmcCell[T]
+}
+
+// NewMPMCQueue 创建容量不小于 capacity 的队列，容量会向上取整为 2 的幂
+func NewMPMCQueue[T any](capacity int) *MPMCQueue[T] {
+	n := roundPow2(capacity)
+	q := &MPMCQueue[T]{mask: uint64(n - 1), cells: make([]mpmcCell[T], n)}
+	for i := range q.cells {
+		q.cells[i].seq.Store(uint64(i))
+	}
+	return q
+}
+
+// TryEnqueue 入队，队列满时返回 false
+func (q *MPMCQueue[T]) TryEnqueue(x T) bool {
+	if q.closed.Load() {
+		panic("container: enqueue on closed queue")
+	}
+	pos := q.tail.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		seq := c.seq.Load()
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			if q.tail.CompareAndSwap(pos, pos+1) {
+				c.val = x
+				c.seq.Store(pos + 1)
+				return true
+			}
+			pos = q.tail.Load()
+		case dif < 0:
+			return false
+		default:
+			pos = q.tail.Load()
+		}
+	}
+}
+
+// Enqueue 入队，队列满时等待
+func (q *MPMCQueue[T]) Enqueue(x T) {
+	for i := 0; !q.TryEnqueue(x); i++ {
+		spin(i)
+	}
+}
+
+// TryDequeue 出队，队列空时返回 false
+func (q *MPMCQueue[T]) TryDequeue() (T, bool) {
+	var zero T
+	pos := q.head.Load()
+	for {
+		c := &q.cells[pos&q.mask]
+		seq := c.seq.Load()
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			if q.head.CompareAndSwap(pos, pos+1) {
+				x := c.val
+				c.val = zero
+				c.seq.Store(pos + q.mask + 1)
+				return x, true
+			}
+			pos = q.head.Load()
+		case dif < 0:
+			return zero, false
+		default:
+			pos = q.head.Load()
+		}
+	}
+}
+
+// Dequeue 出队，队列空时等待；队列已关闭且取空后返回 false
+func (q *MPMCQueue[T]) Dequeue() (T, bool) {
+	for i := 0; ; i++ {
+		if x, ok := q.TryDequeue(); ok {
+			return x, true
+		}
+		if q.closed.Load() {
+			return q.TryDequeue()
+		}
+		spin(i)
+	}
+}
+
+// Close 关闭队列
+func (q *MPMCQueue[T]) Close() {
+	q.closed.Store(true)
+}
+
+// Len 返回队列中的元素个数，并发读写时只是一个近似值
+func (q *MPMCQueue[T]) Len() int {
+	n := int64(q.tail.Load() - q.head.Load())
+	if n < 0 {
+		return 0
+	}
+	return int(n)
+}
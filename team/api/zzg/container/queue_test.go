@@ -0,0 +1,172 @@
+package main
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSPSCQueueTry(t *testing.T) {
+	q := NewSPSCQueue[int](3) // 向上取整为 4
+	for i := 0; i < 4; i++ {
+		if !q.TryEnqueue(i) {
+			t.Fatalf("TryEnqueue(%d) = false", i)
+		}
+	}
+	if q.TryEnqueue(4) {
+		t.Error("TryEnqueue on full queue = true")
+	}
+	for i := 0; i < 4; i++ {
+		if x, ok := q.TryDequeue(); !ok || x != i {
+			t.Fatalf("TryDequeue = %d, %v, want %d", x, ok, i)
+		}
+	}
+	if _, ok := q.TryDequeue(); ok {
+		t.Error("TryDequeue on empty queue = true")
+	}
+}
+
+func TestSPSCQueueStress(t *testing.T) {
+	const n = 100000
+	q := NewSPSCQueue[int](64)
+	go func() {
+		for i := 0; i < n; i++ {
+			q.Enqueue(i)
+		}
+		q.Close()
+	}()
+	want := 0
+	for {
+		x, ok := q.Dequeue()
+		if !ok {
+			break
+		}
+		if x != want {
+			t.Fatalf("Dequeue = %d, want %d", x, want)
+		}
+		want++
+	}
+	if want != n {
+		t.Errorf("received %d items, want %d", want, n)
+	}
+}
+
+func TestMPMCQueueTry(t *testing.T) {
+	q := NewMPMCQueue[int](2)
+	if !q.TryEnqueue(1) || !q.TryEnqueue(2) || q.TryEnqueue(3) {
+		t.Fatal("unexpected TryEnqueue result on capacity 2")
+	}
+	if q.Len() != 2 {
+		t.Errorf("Len = %d, want 2", q.Len())
+	}
+	if x, _ := q.TryDequeue(); x != 1 {
+		t.Errorf("TryDequeue = %d, want 1", x)
+	}
+	if !q.TryEnqueue(3) {
+		t.Error("TryEnqueue after dequeue = false")
+	}
+}
+
+func TestMPMCQueueStress(t *testing.T) {
+	const producers, consumers, per = 4, 4, 20000
+	q := NewMPMCQueue[int](128)
+
+	var pwg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		pwg.Add(1)
+		go func(p int) {
+			defer pwg.Done()
+			for i := 0; i < per; i++ {
+				q.Enqueue(p*per + i)
+			}
+		}(p)
+	}
+	go func() {
+		pwg.Wait()
+		q.Close()
+	}()
+
+	seen := make([][]int, consumers)
+	var cwg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for {
+				x, ok := q.Dequeue()
+				if !ok {
+					return
+				}
+				seen[c] = append(seen[c], x)
+			}
+		}(c)
+	}
+	cwg.Wait()
+
+	got := make([]bool, producers*per)
+	for _, xs := range seen {
+		last := make([]int, producers) // 同一个生产者的元素在每个消费者内应保持顺序
+		for p := range last {
+			last[p] = -1
+		}
+		for _, x := range xs {
+			if got[x] {
+				t.Fatalf("item %d received twice", x)
+			}
+			got[x] = true
+			p := x / per
+			if x <= last[p] {
+				t.Fatalf("item %d after %d from the same producer", x, last[p])
+			}
+			last[p] = x
+		}
+	}
+	for x, ok := range got {
+		if !ok {
+			t.Fatalf("item %d lost", x)
+		}
+	}
+}
+
+func BenchmarkSPSCQueue(b *testing.B) {
+	q := NewSPSCQueue[int](1024)
+	go func() {
+		for i := 0; i < b.N; i++ {
+			q.Enqueue(i)
+		}
+	}()
+	for i := 0; i < b.N; i++ {
+		q.Dequeue()
+	}
+}
+
+func BenchmarkSPSCChannel(b *testing.B) {
+	ch := make(chan int, 1024)
+	go func() {
+		for i := 0; i < b.N; i++ {
+			ch <- i
+		}
+	}()
+	for i := 0; i < b.N; i++ {
+		<-ch
+	}
+}
+
+func BenchmarkMPMCQueue(b *testing.B) {
+	q := NewMPMCQueue[int](1024)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			q.Enqueue(1)
+			q.Dequeue()
+		}
+	})
+}
+
+func BenchmarkMPMCChannel(b *testing.B) {
+	ch := make(chan int, 1024)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			ch <- 1
+			<-ch
+		}
+	})
+}
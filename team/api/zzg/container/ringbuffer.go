@@ -0,0 +1,135 @@
+package main
+
+import (
+	"container/ring"
+	"iter"
+	"math"
+	"time"
+)
+
+// RingBuffer 是建立在 container/ring 上的定长环形缓冲区，写满后覆盖最旧的元素
+type RingBuffer[T any] struct {
+	r *ring.Ring // 下一个写入位置
+	n int        // 已写入的元素个数，最多为容量
+}
+
+// NewRingBuffer 创建容量为 capacity 的环形缓冲区，capacity 必须大于 0
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity <= 0 {
+		panic("container: ring buffer capacity must be positive")
+	}
+	return &RingBuffer[T]{r: ring.New(capacity)}
+}
+
+// Push 写入 x，缓冲区已满时覆盖并返回最旧的元素
+func (b *RingBuffer[T]) Push(x T) (old T, overwritten bool) {
+	if b.n == b.r.Len() {
+		// T 是接口类型且写入过 nil 时 Value 为 nil，不能直接断言
+		old, _ = b.r.Value.(T)
+		overwritten = true
+	} else {
+		b.n++
+	}
+	b.r.Value = x
+	b.r = b.r.Next()
+	return old, overwritten
+}
+
+// Len 返回元素个数
+func (b *RingBuffer[T]) Len() int {
+	return b.n
+}
+
+// Cap 返回容量
+func (b *RingBuffer[T]) Cap() int {
+	return b.r.Len()
+}
+
+// Snapshot 按从旧到新的顺序返回所有元素的副本
+func (b *RingBuffer[T]) Snapshot() []T {
+	out := make([]T, 0, b.n)
+	for x := range b.Iter() {
+		out = append(out, x)
+	}
+	return out
+}
+
+// Iter 按从旧到新的顺序遍历元素，遍历期间不要写入
+func (b *RingBuffer[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		p := b.r.Move(-b.n)
+		for i := 0; i < b.n; i++ {
+			v, _ := p.Value.(T)
+			if !yield(v) {
+				return
+			}
+			p = p.Next()
+		}
+	}
+}
+
+// Sample 是窗口中的一个采样点
+type Sample struct {
+	At    time.Time
+	Value float64
+}
+
+// WindowStats 是窗口内采样的统计值，窗口为空时 Count 为 0，其余字段为 0
+type WindowStats struct {
+	Count int
+	Sum   float64
+	Mean  float64
+	Min   float64
+	Max   float64
+}
+
+// Window 是滑动窗口聚合器，统计最近 N 个采样或最近一段时间内的采样，
+// 用于滚动的监控指标。Stats 每次遍历窗口，复杂度 O(N)。非并发安全
+type Window struct {
+	buf  *RingBuffer[Sample]
+	span time.Duration // 为 0 时只按个数统计
+	now  func() time.Time
+}
+
+// NewCountWindow 创建统计最近 n 个采样的窗口
+func NewCountWindow(n int) *Window {
+	return &Window{buf: NewRingBuffer[Sample](n), now: time.Now}
+}
+
+// NewTimeWindow 创建统计最近 span 时间内采样的窗口，最多保留 maxSamples 个采样；
+// now 为 nil 时使用 time.Now
+func NewTimeWindow(span time.Duration, maxSamples int, now func() time.Time) *Window {
+	if now == nil {
+		now = time.Now
+	}
+	return &Window{buf: NewRingBuffer[Sample](maxSamples), span: span, now: now}
+}
+
+// Add 以当前时间加入一个采样
+func (w *Window) Add(v float64) {
+	w.buf.Push(Sample{At: w.now(), Value: v})
+}
+
+// Stats 返回窗口内采样的统计值
+func (w *Window) Stats() WindowStats {
+	var s WindowStats
+	var since time.Time
+	if w.span > 0 {
+		since = w.now().Add(-w.span)
+	}
+	s.Min, s.Max = math.Inf(1), math.Inf(-1)
+	for x := range w.buf.Iter() {
+		if w.span > 0 && !x.At.After(since) {
+			continue
+		}
+		s.Count++
+		s.Sum += x.Value
+		s.Min = math.Min(s.Min, x.Value)
+		s.Max = math.Max(s.Max, x.Value)
+	}
+	if s.Count == 0 {
+		return WindowStats{}
+	}
+	s.Mean = s.Sum / float64(s.Count)
+	return s
+}
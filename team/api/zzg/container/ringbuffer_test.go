@@ -0,0 +1,84 @@
+package main
+
+import (
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRingBuffer(t *testing.T) {
+	b := NewRingBuffer[int](3)
+	if got := b.Snapshot(); len(got) != 0 {
+		t.Errorf("empty Snapshot = %v", got)
+	}
+	for i := 1; i <= 3; i++ {
+		if _, over := b.Push(i); over {
+			t.Errorf("Push(%d) overwrote", i)
+		}
+	}
+	if old, over := b.Push(4); !over || old != 1 {
+		t.Errorf("Push(4) = %d, %v, want 1, true", old, over)
+	}
+	b.Push(5)
+	if got, want := b.Snapshot(), []int{3, 4, 5}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot = %v, want %v", got, want)
+	}
+	if b.Len() != 3 || b.Cap() != 3 {
+		t.Errorf("Len = %d, Cap = %d", b.Len(), b.Cap())
+	}
+	var got []int
+	for x := range b.Iter() {
+		if x == 5 {
+			break
+		}
+		got = append(got, x)
+	}
+	if want := []int{3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Iter with break = %v, want %v", got, want)
+	}
+}
+
+func TestRingBufferNilInterface(t *testing.T) {
+	b := NewRingBuffer[error](2)
+	b.Push(nil)
+	b.Push(io.EOF)
+	if got, want := b.Snapshot(), []error{nil, io.EOF}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot = %v, want %v", got, want)
+	}
+	if old, over := b.Push(io.ErrUnexpectedEOF); !over || old != nil {
+		t.Errorf("Push = %v, %v, want nil, true", old, over)
+	}
+}
+
+func TestCountWindow(t *testing.T) {
+	w := NewCountWindow(3)
+	if s := w.Stats(); s != (WindowStats{}) {
+		t.Errorf("empty Stats = %+v", s)
+	}
+	for _, v := range []float64{10, 2, 6, 4} {
+		w.Add(v)
+	}
+	want := WindowStats{Count: 3, Sum: 12, Mean: 4, Min: 2, Max: 6}
+	if s := w.Stats(); s != want {
+		t.Errorf("Stats = %+v, want %+v", s, want)
+	}
+}
+
+func TestTimeWindow(t *testing.T) {
+	now := &fakeNow{time.Unix(100, 0)}
+	w := NewTimeWindow(10*time.Second, 100, now.Now)
+	for _, v := range []float64{1, 2, 3, 4} {
+		w.Add(v)
+		now.t = now.t.Add(4 * time.Second)
+	}
+	// 当前时间 116，窗口 (106, 116]，包含 108 和 112 时刻的 3、4
+	want := WindowStats{Count: 2, Sum: 7, Mean: 3.5, Min: 3, Max: 4}
+	if s := w.Stats(); s != want {
+		t.Errorf("Stats = %+v, want %+v", s, want)
+	}
+	now.t = now.t.Add(time.Minute)
+	if s := w.Stats(); s.Count != 0 {
+		t.Errorf("Stats after a minute = %+v", s)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+/*
+	概率型容器，用很小的固定内存近似回答问题：
+		1.BloomFilter 判断元素是否出现过：不存在的一定判断正确，存在的可能误判（假阳性），
+		  误判率在创建时指定，实现了 encoding.BinaryMarshaler/BinaryUnmarshaler；
+		2.CountMinSketch 估计元素出现的次数：估计值只会偏大不会偏小，用来找高频元素。
+	两者都用 FNV-1a 做双重哈希（h1 + i*h2），哈希值与进程无关，序列化后在其它进程中也能继续使用。
+*/
+
+// hash2 返回元素的两个哈希值，第 i 个哈希函数取 h1 + i*h2。
+// h1 是 FNV-1a，h2 由 h1 再混合一次得到，结果与进程无关，反序列化后仍然可用
+func hash2(data []byte) (uint64, uint64) {
+	const offset64, prime64 = 14695981039346656037, 1099511628211
+	h1 := uint64(offset64)
+	for _, c := range data {
+		h1 ^= uint64(c)
+		h1 *= prime64
+	}
+	h2 := h1 ^ h1>>33
+	h2 *= 0xff51afd7ed558ccd
+	h2 ^= h2 >> 33
+	return h1, h2 | 1
+}
+
+// BloomFilter 是布隆过滤器，非并发安全
+type BloomFilter struct {
+	bits []uint64
+	m    uint64 // 位数
+	k    uint64 // 哈希函数个数
+}
+
+// NewBloomFilter 按预计元素个数 n 和期望的误判率 p 创建布隆过滤器：
+// m = -n*ln(p)/(ln2)^2，k = m/n*ln2
+func NewBloomFilter(n int, p float64) *BloomFilter {
+	if n <= 0 || p <= 0 || p >= 1 {
+		panic("container: invalid bloom filter parameters")
+	}
+	m := uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k := uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &BloomFilter{bits: make([]uint64, (m+63)/64), m: m, k: k}
+}
+
+// Add 加入元素
+func (f *BloomFilter) Add(data []byte) {
+	h1, h2 := hash2(data)
+	for i := uint64(0); i < f.k; i++ {
+		b := (h1 + i*h2) % f.m
+		f.bits[b/64] |= 1 << (b % 64)
+	}
+}
+
+// AddString 加入字符串元素
+func (f *BloomFilter) AddString(s string) {
+	f.Add([]byte(s))
+}
+
+// Contains 判断元素是否可能出现过，返回 false 时一定没有出现过
+func (f *BloomFilter) Contains(data []byte) bool {
+	h1, h2 := hash2(data)
+	for i := uint64(0); i < f.k; i++ {
+		b := (h1 + i*h2) % f.m
+		if f.bits[b/64]&(1<<(b%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsString 判断字符串元素是否可能出现过
+func (f *BloomFilter) ContainsString(s string) bool {
+	return f.Contains([]byte(s))
+}
+
+// Cap 返回位数和哈希函数个数
+func (f *BloomFilter) Cap() (m, k uint64) {
+	return f.m, f.k
+}
+
+var errBadSketch = errors.New("container: malformed sketch data")
+
+// MarshalBinary 实现 encoding.BinaryMarshaler，格式为 m、k 和位数组，均为小端序
+func (f *BloomFilter) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 16, 16+8*len(f.bits))
+	binary.LittleEndian.PutUint64(buf[0:], f.m)
+	binary.LittleEndian.PutUint64(buf[8:], f.k)
+	for _, w := range f.bits {
+		buf = binary.LittleEndian.AppendUint64(buf, w)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary 实现 encoding.BinaryUnmarshaler
+func (f *BloomFilter) UnmarshalBinary(data []byte) error {
+	if len(data) < 16 {
+		return errBadSketch
+	}
+	m := binary.LittleEndian.Uint64(data[0:])
+	k := binary.LittleEndian.Uint64(data[8:])
+	data = data[16:]
+	if m == 0 || k == 0 || uint64(len(data)) != (m+63)/64*8 {
+		return errBadSketch
+	}
+	bits := make([]uint64, len(data)/8)
+	for i := range bits {
+		bits[i] = binary.LittleEndian.Uint64(data[8*i:])
+	}
+	f.bits, f.m, f.k = bits, m, k
+	return nil
+}
+
+// CountMinSketch 是 count-min sketch，非并发安全
+type CountMinSketch struct {
+	width  uint64
+	depth  uint64
+	counts []uint64 // depth 行 width 列
+}
+
+// NewCountMinSketch 按误差参数创建：估计值超过真实值 epsilon*总数 的概率不大于 delta，
+// width = e/epsilon，depth = ln(1/delta)
+func NewCountMinSketch(epsilon, delta float64) *CountMinSketch {
+	if epsilon <= 0 || delta <= 0 || delta >= 1 {
+		panic("container: invalid count-min sketch parameters")
+	}
+	w := uint64(math.Ceil(math.E / epsilon))
+	d := uint64(math.Ceil(math.Log(1 / delta)))
+	if d < 1 {
+		d = 1
+	}
+	return &CountMinSketch{width: w, depth: d, counts: make([]uint64, w*d)}
+}
+
+// Add 给元素的计数加 n
+func (s *CountMinSketch) Add(data []byte, n uint64) {
+	h1, h2 := hash2(data)
+	for i := uint64(0); i < s.depth; i++ {
+		s.counts[i*s.width+(h1+i*h2)%s.width] += n
+	}
+}
+
+// AddString 给字符串元素的计数加 n
+func (s *CountMinSketch) AddString(str string, n uint64) {
+	s.Add([]byte(str), n)
+}
+
+// Estimate 返回元素计数的估计值，不小于真实值
+func (s *CountMinSketch) Estimate(data []byte) uint64 {
+	h1, h2 := hash2(data)
+	est := uint64(math.MaxUint64)
+	for i := uint64(0); i < s.depth; i++ {
+		est = min(est, s.counts[i*s.width+(h1+i*h2)%s.width])
+	}
+	return est
+}
+
+// EstimateString 返回字符串元素计数的估计值
+func (s *CountMinSketch) EstimateString(str string) uint64 {
+	return s.Estimate([]byte(str))
+}
+
+// Merge 把 other 的计数合并进来，两者的参数必须相同
+func (s *CountMinSketch) Merge(other *CountMinSketch) error {
+	if s.width != other.width || s.depth != other.depth {
+		return errors.New("container: count-min sketch dimensions do not match")
+	}
+	for i, c := range other.counts {
+		s.counts[i] += c
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding"
+	"fmt"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = (*BloomFilter)(nil)
+	_ encoding.BinaryUnmarshaler = (*BloomFilter)(nil)
+)
+
+func TestBloomFilter(t *testing.T) {
+	const n, p = 10000, 0.01
+	f := NewBloomFilter(n, p)
+	for i := 0; i < n; i++ {
+		f.AddString(fmt.Sprintf("event-%d", i))
+	}
+	for i := 0; i < n; i++ {
+		if !f.ContainsString(fmt.Sprintf("event-%d", i)) {
+			t.Fatalf("false negative for event-%d", i)
+		}
+	}
+	fp := 0
+	for i := n; i < 11*n; i++ {
+		if f.ContainsString(fmt.Sprintf("event-%d", i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / (10 * n); rate > 2*p {
+		t.Errorf("false positive rate = %.4f, want about %.2f", rate, p)
+	}
+}
+
+func TestBloomFilterBinary(t *testing.T) {
+	f := NewBloomFilter(100, 0.001)
+	f.AddString("a")
+	f.AddString("b")
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g BloomFilter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	if !g.ContainsString("a") || !g.ContainsString("b") || g.ContainsString("c") {
+		t.Error("unmarshaled filter differs")
+	}
+	if gm, gk := g.Cap(); gm != f.m || gk != f.k {
+		t.Errorf("Cap = %d, %d, want %d, %d", gm, gk, f.m, f.k)
+	}
+	for _, bad := range [][]byte{nil, data[:15], data[:len(data)-1]} {
+		if err := g.UnmarshalBinary(bad); err == nil {
+			t.Errorf("UnmarshalBinary(%d bytes) succeeded", len(bad))
+		}
+	}
+}
+
+func TestCountMinSketch(t *testing.T) {
+	const eps = 0.001
+	a := NewCountMinSketch(eps, 0.01)
+	b := NewCountMinSketch(eps, 0.01)
+	truth := map[string]uint64{}
+	total := uint64(0)
+	for i := 0; i < 20000; i++ {
+		key := fmt.Sprintf("user-%d", i%500)
+		if i%10 == 0 {
+			key = "hot" // 高频元素
+		}
+		s := a
+		if i%2 == 1 {
+			s = b
+		}
+		s.AddString(key, 1)
+		truth[key]++
+		total++
+	}
+	if err := a.Merge(b); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range truth {
+		got := a.EstimateString(key)
+		if got < want || float64(got-want) > eps*float64(total)*2 {
+			t.Errorf("Estimate(%s) = %d, want about %d", key, got, want)
+		}
+	}
+	if a.EstimateString("hot") < 2000 {
+		t.Errorf("Estimate(hot) = %d, want >= 2000", a.EstimateString("hot"))
+	}
+	if err := a.Merge(NewCountMinSketch(0.1, 0.01)); err == nil {
+		t.Error("Merge of mismatched sketches succeeded")
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"container/list"
+	"container/ring"
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+	TimerWheel 是分层时间轮，用来管理大量超时（如几十万个连接的 deadline），
+	代替每个对象一个 time.Timer：
+		1.每层是一个 container/ring 环，环上每个槽位是一条 container/list 链表；
+		2.第 0 层每个槽位代表一个 tick，第 i 层每个槽位代表 64^i 个 tick，共 4 层，
+		  超出范围的定时器先放在最高层的最后一个槽位，转到时再重新分配；
+		3.Schedule 和 Cancel 都是 O(1)（ring.Move 最多走 63 步）；
+		4.时间由 Advance 驱动，当前时间通过构造时传入的 now 获取，
+		  测试中用假时钟调用 Advance 即可，不需要真的 sleep；Run 用 time.Ticker 定期调用 Advance。
+	到期的回调在 Advance 所在的 goroutine 中、释放锁之后依次执行，回调里可以再 Schedule/Cancel，
+	耗时的回调应自己另起 goroutine。
+*/
+
+const (
+	wheelBits   = 6
+	wheelSlots  = 1 << wheelBits
+	wheelMask   = wheelSlots - 1
+	wheelLevels = 4
+)
+
+// TimerHandle 是 Schedule 返回的句柄，用于取消
+type TimerHandle struct {
+	expire int64 // 到期的 tick
+	fn     func()
+	slot   *list.List
+	elem   *list.Element
+}
+
+type wheelLevel struct {
+	cur *ring.Ring // 当前 tick 所在的槽位，Value 为 *list.List
+}
+
+// TimerWheel 是分层时间轮
+type TimerWheel struct {
+	mu      sync.Mutex
+	tick    time.Duration
+	now     func() time.Time
+	start   time.Time
+	current int64 // 已经走过的 tick 数
+	levels  [wheelLevels]wheelLevel
+	n       int
+}
+
+// NewTimerWheel 创建精度为 tick 的时间轮，now 为 nil 时使用 time.Now
+func NewTimerWheel(tick time.Duration, now func() time.Time) *TimerWheel {
+	if tick <= 0 {
+		panic("container: timer wheel tick must be positive")
+	}
+	if now == nil {
+		now = time.Now
+	}
+	w := &TimerWheel{tick: tick, now: now, start: now()}
+	for i := range w.levels {
+		r := ring.New(wheelSlots)
+		for j := 0; j < wheelSlots; j++ {
+			r.Value = list.New()
+			r = r.Next()
+		}
+		w.levels[i].cur = r
+	}
+	return w
+}
+
+// Schedule 在 delay 之后执行 fn，不足一个 tick 的按一个 tick 计算
+func (w *TimerWheel) Schedule(delay time.Duration, fn func()) *TimerHandle {
+	ticks := int64((delay + w.tick - 1) / w.tick)
+	if ticks < 1 {
+		ticks = 1
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	// 从当前时间算起，而不是从上次 Advance 走到的 tick 算起，Run 落后时定时器不会提前触发
+	base := max(int64(w.now().Sub(w.start)/w.tick), w.current)
+	h := &TimerHandle{expire: base + ticks, fn: fn}
+	w.place(h)
+	w.n++
+	return h
+}
+
+// place 把定时器放到能容纳它的最低一层
+func (w *TimerWheel) place(h *TimerHandle) {
+	lvl := 0
+	offset := h.expire - w.current
+	for ; lvl < wheelLevels; lvl++ {
+		shift := uint(wheelBits * lvl)
+		offset = h.expire>>shift - w.current>>shift
+		if offset < wheelSlots {
+			break
+		}
+	}
+	if lvl == wheelLevels {
+		lvl, offset = wheelLevels-1, wheelSlots-1
+	}
+	h.slot = w.levels[lvl].cur.Move(int(offset)).Value.(*list.List)
+	h.elem = h.slot.PushBack(h)
+}
+
+// Cancel 取消定时器，定时器已经执行或已被取消时返回 false
+func (w *TimerWheel) Cancel(h *TimerHandle) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if h == nil || h.slot == nil {
+		return false
+	}
+	h.slot.Remove(h.elem)
+	h.slot, h.elem = nil, nil
+	w.n--
+	return true
+}
+
+// Len 返回尚未执行的定时器个数
+func (w *TimerWheel) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.n
+}
+
+// Advance 按当前时间推进时间轮，执行所有到期的定时器，返回执行的个数
+func (w *TimerWheel) Advance() int {
+	w.mu.Lock()
+	target := int64(w.now().Sub(w.start) / w.tick)
+	var due []*TimerHandle
+	for w.current < target {
+		due = w.step(due)
+	}
+	w.mu.Unlock()
+	for _, h := range due {
+		h.fn()
+	}
+	return len(due)
+}
+
+// step 前进一个 tick，把到期的定时器追加到 due
+func (w *TimerWheel) step(due []*TimerHandle) []*TimerHandle {
+	w.current++
+	w.levels[0].cur = w.levels[0].cur.Next()
+	top := 0
+	for i := 1; i < wheelLevels && w.current&(1<<(wheelBits*i)-1) == 0; i++ {
+		w.levels[i].cur = w.levels[i].cur.Next()
+		top = i
+	}
+	// 从高到低把转到的槽位里的定时器重新分配到低层
+	for i := top; i >= 1; i-- {
+		slot := w.levels[i].cur.Value.(*list.List)
+		for e := slot.Front(); e != nil; {
+			next := e.Next()
+			h := slot.Remove(e).(*TimerHandle)
+			if h.expire <= w.current {
+				due = w.fire(due, h)
+			} else {
+				w.place(h)
+			}
+			e = next
+		}
+	}
+	slot := w.levels[0].cur.Value.(*list.List)
+	for e := slot.Front(); e != nil; {
+		next := e.Next()
+		due = w.fire(due, slot.Remove(e).(*TimerHandle))
+		e = next
+	}
+	return due
+}
+
+func (w *TimerWheel) fire(due []*TimerHandle, h *TimerHandle) []*TimerHandle {
+	h.slot, h.elem = nil, nil
+	w.n--
+	return append(due, h)
+}
+
+// Run 每个 tick 调用一次 Advance，直到 ctx 结束
+func (w *TimerWheel) Run(ctx context.Context) {
+	t := time.NewTicker(w.tick)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			w.Advance()
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestTimerWheelFiresOnTime(t *testing.T) {
+	now := &fakeNow{time.Unix(0, 0)}
+	w := NewTimerWheel(time.Millisecond, now.Now)
+
+	r := rand.New(rand.NewSource(1))
+	const n = 2000
+	want := make([]int64, n)
+	got := make([]int64, n)
+	for i := 0; i < n; i++ {
+		// 覆盖第 0 层和第 1 层
+		delay := time.Duration(r.Int63n(int64(300*time.Millisecond))) + time.Microsecond
+		want[i] = int64((delay + time.Millisecond - 1) / time.Millisecond)
+		w.Schedule(delay, func() { got[i] = int64(now.t.Sub(time.Unix(0, 0)) / time.Millisecond) })
+	}
+	for tick := 0; tick < 300; tick++ {
+		now.t = now.t.Add(time.Millisecond)
+		w.Advance()
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("timer %d fired at tick %d, want %d", i, got[i], want[i])
+		}
+	}
+	if w.Len() != 0 {
+		t.Errorf("Len = %d, want 0", w.Len())
+	}
+}
+
+func TestTimerWheelScheduleBehind(t *testing.T) {
+	now := &fakeNow{time.Unix(0, 0)}
+	w := NewTimerWheel(time.Millisecond, now.Now)
+	// 时钟走到 10ms 但还没有 Advance，5ms 的定时器应在 15ms 触发
+	now.t = now.t.Add(10 * time.Millisecond)
+	var at time.Duration
+	w.Schedule(5*time.Millisecond, func() { at = now.t.Sub(time.Unix(0, 0)) })
+	for i := 0; i < 10; i++ {
+		now.t = now.t.Add(time.Millisecond)
+		w.Advance()
+	}
+	if at != 15*time.Millisecond {
+		t.Errorf("fired at %v, want 15ms", at)
+	}
+}
+
+func TestTimerWheelCancel(t *testing.T) {
+	now := &fakeNow{time.Unix(0, 0)}
+	w := NewTimerWheel(time.Second, now.Now)
+	fired := 0
+	h1 := w.Schedule(10*time.Second, func() { fired++ })
+	h2 := w.Schedule(5*time.Minute, func() { fired++ })
+	w.Schedule(0, func() { fired++ })
+	if !w.Cancel(h1) || w.Cancel(h1) {
+		t.Error("Cancel should succeed exactly once")
+	}
+	if w.Len() != 2 {
+		t.Errorf("Len = %d, want 2", w.Len())
+	}
+
+	now.t = now.t.Add(time.Hour) // 一次推进多个 tick
+	if n := w.Advance(); n != 2 || fired != 2 {
+		t.Errorf("Advance = %d, fired = %d, want 2, 2", n, fired)
+	}
+	if w.Cancel(h2) {
+		t.Error("Cancel after firing should return false")
+	}
+}
+
+func TestTimerWheelScheduleFromCallback(t *testing.T) {
+	now := &fakeNow{time.Unix(0, 0)}
+	w := NewTimerWheel(time.Second, now.Now)
+	var ticks []int
+	var again func()
+	again = func() {
+		ticks = append(ticks, int(now.t.Unix()))
+		if len(ticks) < 3 {
+			w.Schedule(2*time.Second, again)
+		}
+	}
+	w.Schedule(time.Second, again)
+	for i := 0; i < 10; i++ {
+		now.t = now.t.Add(time.Second)
+		w.Advance()
+	}
+	if len(ticks) != 3 || ticks[0] != 1 || ticks[1] != 3 || ticks[2] != 5 {
+		t.Errorf("fired at %v, want [1 3 5]", ticks)
+	}
+}
+
+func TestTimerWheelOverflow(t *testing.T) {
+	if testing.Short() {
+		t.Skip("steps through 16M ticks")
+	}
+	now := &fakeNow{time.Unix(0, 0)}
+	w := NewTimerWheel(time.Millisecond, now.Now)
+	const ticks = wheelSlots*wheelSlots*wheelSlots*wheelSlots + 5
+	var at time.Duration
+	w.Schedule(ticks*time.Millisecond, func() { at = now.t.Sub(time.Unix(0, 0)) })
+	now.t = now.t.Add((ticks - 1) * time.Millisecond)
+	if w.Advance() != 0 {
+		t.Fatal("fired too early")
+	}
+	now.t = now.t.Add(time.Millisecond)
+	if w.Advance() != 1 || at != ticks*time.Millisecond {
+		t.Errorf("fired at %v, want %v", at, ticks*time.Millisecond)
+	}
+}
+
+func BenchmarkTimerWheelSchedule(b *testing.B) {
+	w := NewTimerWheel(time.Millisecond, nil)
+	for i := 0; i < b.N; i++ {
+		w.Cancel(w.Schedule(time.Duration(i%60000)*time.Millisecond, func() {}))
+	}
+}
+
+func BenchmarkTimeAfterFunc(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		time.AfterFunc(time.Duration(i%60000)*time.Millisecond+time.Minute, func() {}).Stop()
+	}
+}
@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
+
+	"github.com/ZcmOrg/demo-go-base/team/api/zzg/retry"
 )
 
 // 模拟一个最小执行时间的阻塞函数
@@ -40,19 +43,52 @@ func Add2(ctx context.Context, b int) int {
 	}
 	return res
 }
+
+// inc 每一步的耗时，测试中可以调小
+var incDelay = 1 * time.Second
+
+// 可以被取消的 inc：ctx 结束时立即返回，而不是睡满一秒再检查
+func incCtx(ctx context.Context, a int) (int, error) {
+	if err := Sleep(ctx, incDelay); err != nil {
+		return 0, err
+	}
+	return a + 1, nil
+}
+
+// 用任务池改写 Add1 的循环：a 次 inc 最多 limit 个同时执行，任意一次失败就取消其余的
+func AddPool(ctx context.Context, a, limit int) (int, error) {
+	p := NewPool[int](ctx, limit)
+	for i := 0; i < a; i++ {
+		p.Go(func(ctx context.Context) (int, error) {
+			return incCtx(ctx, 0)
+		})
+	}
+	results, err := p.Wait()
+	if err != nil {
+		return 0, err
+	}
+	res := 0
+	for _, r := range results {
+		res += r.Value
+	}
+	return res, nil
+}
+
 func main() {
 	{
 		// 使用开放的 API 计算 a+b
 		timeout := 2 * time.Second
-		ctx, _ := context.WithTimeout(context.Background(), timeout)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		res := Add1(ctx, 1)
+		cancel()
 		fmt.Printf("result: %d\n", res)
 	}
 	{
 		// 使用开放的 API 计算 a+b
 		timeout := 2 * time.Second
-		ctx, _ := context.WithTimeout(context.Background(), timeout)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		res := Add1(ctx, 3)
+		cancel()
 		fmt.Printf("result: %d\n", res)
 	}
 	{
@@ -62,4 +98,43 @@ func main() {
 		res := Add2(ctx, 1)
 		fmt.Printf("result: %d\n", res)
 	}
+	{
+		// 任务池：6 次 inc 每次最多 3 个同时执行，耗时约 2 秒
+		timeout := 3 * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		res, err := AddPool(ctx, 6, 3)
+		cancel()
+		fmt.Printf("result: %d, err: %v\n", res, err)
+	}
+	{
+		// 分步执行：超时和手动取消返回不同的错误，并带上已完成的步数
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		res, err := AddSteps(ctx, 3)
+		cancel()
+		var se *StepError
+		if errors.As(err, &se) {
+			fmt.Printf("result: %d, timeout: %t, canceled: %t, done: %d/%d\n", res, se.Timeout(), se.Canceled(), se.Done, se.Total)
+		}
+	}
+	{
+		// 重试：第一次失败，等待 100ms 后重试；第一次最多分到 5 秒的一半
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		p := retry.Policy{MaxAttempts: 2, Initial: 100 * time.Millisecond}
+		res, err := retry.DoValue(ctx, p, func(ctx context.Context, attempt int) (int, error) {
+			if attempt == 0 {
+				return 0, errors.New("temporary failure")
+			}
+			return AddSteps(ctx, 2)
+		})
+		cancel()
+		fmt.Printf("result: %d, err: %v\n", res, err)
+	}
+	{
+		// 请求级别的值：请求结束后，后台任务仍然带着 request id 继续执行
+		ctx, cancel := context.WithCancel(WithMetadata(context.Background(), MDRequestID, "req-1"))
+		bg := DetachWith(ctx)
+		cancel()
+		res, err := AddSteps(bg, 1)
+		fmt.Printf("request: %s, result: %d, err: %v\n", MetadataValue(bg, MDRequestID), res, err)
+	}
 }
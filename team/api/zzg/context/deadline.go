@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+	调用 HTTP 服务或者像 os.go 那样用 exec.Command 启动子进程时，ctx 的截止时间会丢失，
+	对方不知道调用方最多还能等多久。这里把截止时间带过去：
+		1.HTTP：DeadlineTransport 把剩余时间（毫秒）写到 X-Request-Timeout-Ms 请求头，
+		  DeadlineHandler 读出来给请求的 ctx 加上超时；用剩余时间而不是绝对时间，不受两台机器时钟差的影响；
+		2.子进程：CommandContext 把截止时间（RFC3339Nano）写到环境变量 CTX_DEADLINE，
+		  子进程用 WithEnvDeadline 恢复；同一台机器，用绝对时间不受子进程启动耗时的影响；
+		3.CommandContext 把子进程放到单独的进程组，ctx 结束时杀掉整个进程组，
+		  子进程再启动的进程不会变成孤儿继续运行（仅 unix）。
+*/
+
+const (
+	DeadlineHeader = "X-Request-Timeout-Ms"
+	DeadlineEnv    = "CTX_DEADLINE"
+)
+
+// DeadlineHandler 按 X-Request-Timeout-Ms 头给请求的 ctx 加上超时，没有这个头时不改变 ctx
+func DeadlineHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ms, err := strconv.ParseInt(r.Header.Get(DeadlineHeader), 10, 64)
+		if err != nil || ms < 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(ms)*time.Millisecond)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// DeadlineTransport 把请求 ctx 的剩余时间写到 X-Request-Timeout-Ms 头，已经超时的请求不再发送
+type DeadlineTransport struct {
+	Base http.RoundTripper // nil 时为 http.DefaultTransport
+}
+
+func (t *DeadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx := req.Context()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return base.RoundTrip(req)
+	}
+	left := time.Until(deadline)
+	if left <= 0 || ctx.Err() != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		// 在截止时间之前被取消时返回取消的原因
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+		return nil, context.DeadlineExceeded
+	}
+	req = req.Clone(ctx)
+	req.Header.Set(DeadlineHeader, strconv.FormatInt(left.Milliseconds(), 10))
+	return base.RoundTrip(req)
+}
+
+// CommandContext 同 exec.CommandContext，另外把截止时间写到子进程的 CTX_DEADLINE 环境变量，
+// ctx 结束时杀掉整个进程组。调用方修改 Env 时请在返回的 cmd.Env 上追加
+func CommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	if deadline, ok := ctx.Deadline(); ok {
+		env := slices.DeleteFunc(cmd.Environ(), func(e string) bool {
+			return strings.HasPrefix(e, DeadlineEnv+"=")
+		})
+		cmd.Env = append(env, DeadlineEnv+"="+deadline.Format(time.RFC3339Nano))
+	}
+	setProcessGroup(cmd)
+	return cmd
+}
+
+// WithEnvDeadline 在子进程中按 CTX_DEADLINE 给 parent 加上截止时间，没有或格式错误时只加 cancel
+func WithEnvDeadline(parent context.Context) (context.Context, context.CancelFunc) {
+	if deadline, err := time.Parse(time.RFC3339Nano, os.Getenv(DeadlineEnv)); err == nil {
+		return context.WithDeadline(parent, deadline)
+	}
+	return context.WithCancel(parent)
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package main
+
+import "os/exec"
+
+// setProcessGroup 在非 unix 系统上不做处理，取消时只杀掉子进程本身
+func setProcessGroup(cmd *exec.Cmd) {}
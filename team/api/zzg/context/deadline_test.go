@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDeadlineHTTP(t *testing.T) {
+	srv := httptest.NewServer(DeadlineHandler(MetadataHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if !ok {
+			fmt.Fprint(w, "none")
+			return
+		}
+		fmt.Fprint(w, time.Until(deadline).Milliseconds(), ",", MetadataValue(r.Context(), MDRequestID))
+	}), MDRequestID)))
+	defer srv.Close()
+
+	// 两个 Transport 可以叠加使用
+	client := &http.Client{Transport: &DeadlineTransport{Base: &MetadataTransport{}}}
+	get := func(ctx context.Context) (string, error) {
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		b, err := io.ReadAll(resp.Body)
+		return string(b), err
+	}
+
+	if got, err := get(context.Background()); err != nil || got != "none" {
+		t.Errorf("without deadline = %q, %v, want none", got, err)
+	}
+
+	ctx, cancel := context.WithTimeout(WithMetadata(context.Background(), MDRequestID, "r-1"), 2*time.Second)
+	defer cancel()
+	got, err := get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ms int64
+	var id string
+	if _, err := fmt.Sscanf(got, "%d,%s", &ms, &id); err != nil || ms <= 1000 || ms > 2000 || id != "r-1" {
+		t.Errorf("server saw %q, want remaining budget in (1000, 2000] ms and r-1", got)
+	}
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	if _, err := get(expired); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expired request err = %v, want DeadlineExceeded", err)
+	}
+
+	// 还没到截止时间就被取消，返回取消的原因而不是 DeadlineExceeded
+	errShutdown := errors.New("shutting down")
+	canceled, cancelCause := context.WithCancelCause(context.Background())
+	canceled, cancel = context.WithTimeout(canceled, time.Minute)
+	defer cancel()
+	cancelCause(errShutdown)
+	req, _ := http.NewRequestWithContext(canceled, http.MethodGet, srv.URL, nil)
+	if _, err := (&DeadlineTransport{}).RoundTrip(req); !errors.Is(err, errShutdown) {
+		t.Errorf("canceled request err = %v, want %v", err, errShutdown)
+	}
+}
+
+func TestDeadlineHandlerBadHeader(t *testing.T) {
+	for _, v := range []string{"", "abc", "-5"} {
+		var has bool
+		h := DeadlineHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, has = r.Context().Deadline()
+		}))
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(DeadlineHeader, v)
+		h.ServeHTTP(httptest.NewRecorder(), req)
+		if has {
+			t.Errorf("header %q set a deadline", v)
+		}
+	}
+}
+
+func TestWithEnvDeadline(t *testing.T) {
+	want := time.Now().Add(time.Minute).Round(0)
+	t.Setenv(DeadlineEnv, want.Format(time.RFC3339Nano))
+	ctx, cancel := WithEnvDeadline(context.Background())
+	defer cancel()
+	if got, ok := ctx.Deadline(); !ok || !got.Equal(want) {
+		t.Errorf("Deadline = %v, %t, want %v", got, ok, want)
+	}
+
+	t.Setenv(DeadlineEnv, strconv.Itoa(60))
+	ctx, cancel = WithEnvDeadline(context.Background())
+	defer cancel()
+	if _, ok := ctx.Deadline(); ok {
+		t.Error("malformed CTX_DEADLINE should be ignored")
+	}
+}
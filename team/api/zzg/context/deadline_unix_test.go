@@ -0,0 +1,39 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandContextEnv(t *testing.T) {
+	t.Setenv(DeadlineEnv, "stale")
+	deadline := time.Now().Add(time.Minute)
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	out, err := CommandContext(ctx, "sh", "-c", "echo $"+DeadlineEnv).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(string(out)))
+	if err != nil || !got.Equal(deadline) {
+		t.Errorf("child saw %q, want %v", out, deadline)
+	}
+}
+
+func TestCommandContextKillsGroup(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	// sh 启动的 sleep 继承了 stdout，只杀 sh 的话 Output 会一直等到 sleep 结束
+	start := time.Now()
+	_, err := CommandContext(ctx, "sh", "-c", "sleep 30; true").Output()
+	if err == nil {
+		t.Fatal("Output succeeded, want the command to be killed")
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Errorf("Output returned after %v, grandchild was not killed", d)
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+/*
+	main 里 ctx, _ := context.WithTimeout(...) 丢掉了 cancel，这是最常见的 context 泄漏：
+	在超时之前，context 和它的计时器、以及所有等待 Done() 的 goroutine 都不会被释放。
+	LeakChecker 是测试辅助工具，测试中用它代替 context 包来创建 context：
+		1.记录每个 cancel 函数的创建位置，测试结束时还没有被调用的会报错；
+		2.记录调用被跟踪 context 的 Done() 的 goroutine 和调用 Done() 的函数，测试结束时
+		  仍在该函数中阻塞在 select/chan receive 上的会连同调用栈一起报错；
+		3.在 NewLeakChecker 时注册 t.Cleanup，不需要手动调用。
+	只跟踪通过 LeakChecker 的方法创建的 context。用 context.WithCancel 等从被跟踪的 context
+	派生出的 context 不会被跟踪，需要检查的子 context 也要用 LeakChecker 创建。
+	通过 goroutine 编号判断，不要在 t.Parallel() 的测试里共用同一个 LeakChecker。
+*/
+
+// leakGrace 是测试结束后等待 goroutine 自行退出的时间
+const leakGrace = 200 * time.Millisecond
+
+// LeakChecker 跟踪测试中创建的 context
+type LeakChecker struct {
+	t testing.TB
+
+	mu      sync.Mutex
+	cancels []*trackedCancel
+	waiters map[int64]map[string]string // 调用过 Done() 的 goroutine -> 调用 Done() 的函数 -> 创建 context 的位置
+}
+
+type trackedCancel struct {
+	site   string
+	called atomic.Bool
+}
+
+// trackedCtx 包装 context，记录调用 Done() 的 goroutine
+type trackedCtx struct {
+	context.Context
+	c    *LeakChecker
+	site string
+}
+
+func (x *trackedCtx) Done() <-chan struct{} {
+	fn := "unknown"
+	if pc, _, _, ok := runtime.Caller(1); ok {
+		fn = runtime.FuncForPC(pc).Name()
+	}
+	id := goid()
+	x.c.mu.Lock()
+	if x.c.waiters[id] == nil {
+		x.c.waiters[id] = make(map[string]string)
+	}
+	x.c.waiters[id][fn] = x.site
+	x.c.mu.Unlock()
+	return x.Context.Done()
+}
+
+// NewLeakChecker 创建 LeakChecker，并在测试结束时检查泄漏
+func NewLeakChecker(t testing.TB) *LeakChecker {
+	c := &LeakChecker{t: t, waiters: make(map[int64]map[string]string)}
+	t.Cleanup(c.check)
+	return c
+}
+
+// Background 返回被跟踪的 context.Background()
+func (c *LeakChecker) Background() context.Context {
+	return c.wrap(context.Background(), callSite())
+}
+
+// WithCancel 同 context.WithCancel，cancel 必须在测试结束前调用
+func (c *LeakChecker) WithCancel(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	return c.track(ctx, cancel, callSite())
+}
+
+// WithCancelCause 同 context.WithCancelCause，cancel 必须在测试结束前调用
+func (c *LeakChecker) WithCancelCause(parent context.Context) (context.Context, context.CancelCauseFunc) {
+	ctx, cancel := context.WithCancelCause(parent)
+	site := callSite()
+	tc := c.register(site)
+	return c.wrap(ctx, site), func(cause error) {
+		tc.called.Store(true)
+		cancel(cause)
+	}
+}
+
+// WithTimeout 同 context.WithTimeout，即使已经超时 cancel 也必须调用
+func (c *LeakChecker) WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(parent, d)
+	return c.track(ctx, cancel, callSite())
+}
+
+// WithDeadline 同 context.WithDeadline，即使已经超时 cancel 也必须调用
+func (c *LeakChecker) WithDeadline(parent context.Context, d time.Time) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithDeadline(parent, d)
+	return c.track(ctx, cancel, callSite())
+}
+
+func (c *LeakChecker) track(ctx context.Context, cancel context.CancelFunc, site string) (context.Context, context.CancelFunc) {
+	tc := c.register(site)
+	return c.wrap(ctx, site), func() {
+		tc.called.Store(true)
+		cancel()
+	}
+}
+
+func (c *LeakChecker) register(site string) *trackedCancel {
+	tc := &trackedCancel{site: site}
+	c.mu.Lock()
+	c.cancels = append(c.cancels, tc)
+	c.mu.Unlock()
+	return tc
+}
+
+func (c *LeakChecker) wrap(ctx context.Context, site string) context.Context {
+	return &trackedCtx{Context: ctx, c: c, site: site}
+}
+
+func (c *LeakChecker) check() {
+	c.t.Helper()
+	c.mu.Lock()
+	cancels := append([]*trackedCancel(nil), c.cancels...)
+	c.mu.Unlock()
+	for _, tc := range cancels {
+		if !tc.called.Load() {
+			c.t.Errorf("context created at %s: cancel func was never called", tc.site)
+		}
+	}
+
+	self := goid()
+	deadline := time.Now().Add(leakGrace)
+	for {
+		blocked := c.blockedWaiters(self)
+		if len(blocked) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			for _, b := range blocked {
+				c.t.Errorf("goroutine still blocked after Done() on context created at %s:\n%s", b.site, b.stack)
+			}
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+type blockedGoroutine struct {
+	site  string
+	stack string
+}
+
+// blockedWaiters 返回仍在调用 Done() 的函数中阻塞在 channel 上的 goroutine。
+// 调用过 Done() 之后转去等待其它 channel 的 goroutine 不算
+func (c *LeakChecker) blockedWaiters(self int64) []blockedGoroutine {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var out []blockedGoroutine
+	for id, stack := range goroutines() {
+		fns, ok := c.waiters[id]
+		if !ok || id == self {
+			continue
+		}
+		state := goroutineState(stack)
+		if !strings.HasPrefix(state, "select") && !strings.HasPrefix(state, "chan receive") {
+			continue
+		}
+		if site, ok := fns[blockedIn(stack)]; ok {
+			out = append(out, blockedGoroutine{site: site, stack: stack})
+		}
+	}
+	return out
+}
+
+// callSite 返回调用 LeakChecker 方法的位置
+func callSite() string {
+	_, file, line, ok := runtime.Caller(2)
+	if !ok {
+		return "unknown"
+	}
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
+// goid 从调用栈的第一行 "goroutine 123 [running]:" 中取出当前 goroutine 的编号
+func goid() int64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseInt(string(b), 10, 64)
+	return id
+}
+
+// goroutines 返回所有 goroutine 的编号和调用栈
+func goroutines() map[int64]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	out := make(map[int64]string)
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		rest, ok := strings.CutPrefix(g, "goroutine ")
+		if !ok {
+			continue
+		}
+		idStr, _, _ := strings.Cut(rest, " ")
+		if id, err := strconv.ParseInt(idStr, 10, 64); err == nil {
+			out[id] = g
+		}
+	}
+	return out
+}
+
+// blockedIn 返回调用栈中最内层的非 runtime 函数，即阻塞所在的函数
+func blockedIn(stack string) string {
+	lines := strings.Split(stack, "\n")
+	// 第一行是 "goroutine 123 [select]:"，之后函数和 file:line 交替出现
+	for i := 1; i < len(lines); i += 2 {
+		fn := lines[i]
+		if j := strings.LastIndexByte(fn, '('); j > 0 {
+			fn = fn[:j]
+		}
+		if !strings.HasPrefix(fn, "runtime.") {
+			return fn
+		}
+	}
+	return ""
+}
+
+// goroutineState 返回 "goroutine 123 [chan receive]:" 中括号里的状态
+func goroutineState(stack string) string {
+	i := strings.IndexByte(stack, '[')
+	j := strings.IndexByte(stack, ']')
+	if i < 0 || j < i {
+		return ""
+	}
+	return stack[i+1 : j]
+}
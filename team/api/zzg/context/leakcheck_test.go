@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTB 记录错误，Cleanup 由测试手动执行
+type fakeTB struct {
+	testing.TB
+	errs     []string
+	cleanups []func()
+}
+
+func (f *fakeTB) Helper()                        {}
+func (f *fakeTB) Cleanup(fn func())              { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeTB) Errorf(format string, a ...any) { f.errs = append(f.errs, fmt.Sprintf(format, a...)) }
+
+func (f *fakeTB) runCleanups() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestLeakCheckerClean(t *testing.T) {
+	defer func(d time.Duration) { incDelay = d }(incDelay)
+	incDelay = time.Millisecond
+
+	lc := NewLeakChecker(t)
+	ctx, cancel := lc.WithTimeout(lc.Background(), time.Second)
+	defer cancel()
+	if res, err := AddSteps(ctx, 3); res != 3 || err != nil {
+		t.Fatalf("AddSteps = %d, %v, want 3, nil", res, err)
+	}
+	if res, err := AddPool(ctx, 3, 2); res != 3 || err != nil {
+		t.Fatalf("AddPool = %d, %v, want 3, nil", res, err)
+	}
+
+	// 等待 Done() 的 goroutine 在 cancel 后退出，不算泄漏
+	child, stop := lc.WithCancelCause(ctx)
+	exited := make(chan struct{})
+	go func() {
+		<-child.Done()
+		close(exited)
+	}()
+	stop(nil)
+	<-exited
+}
+
+func TestLeakCheckerMissingCancel(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	lc := NewLeakChecker(tb)
+	_, cancel := lc.WithCancel(lc.Background())
+	lc.WithDeadline(lc.Background(), time.Now().Add(time.Hour))
+	tb.runCleanups()
+	cancel()
+
+	if len(tb.errs) != 2 {
+		t.Fatalf("errs = %q, want 2", tb.errs)
+	}
+	for _, e := range tb.errs {
+		if !strings.Contains(e, "leakcheck_test.go:") || !strings.Contains(e, "cancel func was never called") {
+			t.Errorf("err = %q, want call site and message", e)
+		}
+	}
+}
+
+func TestLeakCheckerBlockedGoroutine(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	lc := NewLeakChecker(tb)
+	ctx, cancel := lc.WithCancel(lc.Background())
+	started := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		done := ctx.Done()
+		close(started)
+		<-done
+		close(exited)
+	}()
+	<-started
+
+	// 忘记在测试结束前 cancel：既报 cancel 未调用，也报阻塞的 goroutine
+	tb.runCleanups()
+	cancel()
+	<-exited
+
+	if len(tb.errs) != 2 {
+		t.Fatalf("errs = %q, want 2", tb.errs)
+	}
+	if !strings.Contains(tb.errs[1], "goroutine still blocked") || !strings.Contains(tb.errs[1], "TestLeakCheckerBlockedGoroutine") {
+		t.Errorf("err = %q, want blocked goroutine with its stack", tb.errs[1])
+	}
+}
+
+// waitOn 在另一个函数中阻塞，不是等待 Done()
+func waitOn(ch chan struct{}) {
+	<-ch
+}
+
+func TestLeakCheckerOtherChannel(t *testing.T) {
+	tb := &fakeTB{TB: t}
+	lc := NewLeakChecker(tb)
+	ctx, cancel := lc.WithCancel(lc.Background())
+	cancel()
+	block := make(chan struct{})
+	started := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		close(started)
+		waitOn(block)
+		close(exited)
+	}()
+	<-started
+
+	// 调用过 Done() 但阻塞在无关 channel 上的 goroutine 不算泄漏
+	tb.runCleanups()
+	close(block)
+	<-exited
+	if len(tb.errs) != 0 {
+		t.Errorf("errs = %q, want none", tb.errs)
+	}
+}
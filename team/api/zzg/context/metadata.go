@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+/*
+	Metadata 是随请求传递的字符串键值，例如 request id、租户、用户，可以跨进程传递：
+		1.HTTP：MetadataTransport 把 ctx 里的 Metadata 写到 X-Md-<Key> 请求头，
+		  MetadataHandler 从请求头读出来放进请求的 ctx，只接受列出的 key；
+		2.子进程：InjectCmd 把 Metadata 写到 exec.Cmd 的环境变量 MD_<KEY>，
+		  子进程用 MetadataFromEnviron(os.Environ()) 读出来；
+		3.key 不区分大小写，'_' 和 '-' 等价，统一保存为小写加 '-'；value 做 URL 转义，
+		  可以包含换行等请求头和环境变量中不允许的字符。
+	Metadata 放进 ctx 后不应再修改，WithMetadata 每次都复制一份。
+*/
+
+// 常用的 Metadata key
+const (
+	MDRequestID = "request-id"
+	MDTenant    = "tenant"
+	MDUser      = "user"
+)
+
+const (
+	MetadataHeaderPrefix = "X-Md-"
+	MetadataEnvPrefix    = "MD_"
+)
+
+// Metadata 是请求级别的字符串键值
+type Metadata map[string]string
+
+var metadataKey = NewKey[Metadata]("metadata")
+
+func normalizeKey(k string) string {
+	return strings.ToLower(strings.ReplaceAll(k, "_", "-"))
+}
+
+// WithMetadata 返回在原有 Metadata 上加入 kv（key1, value1, key2, value2...）的 ctx
+func WithMetadata(ctx context.Context, kv ...string) context.Context {
+	if len(kv)%2 != 0 {
+		panic("WithMetadata: odd number of arguments")
+	}
+	md := make(Metadata, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		md[kv[i]] = kv[i+1]
+	}
+	return MergeMetadata(ctx, md)
+}
+
+// MergeMetadata 返回在原有 Metadata 上合并 md 的 ctx，相同的 key 以 md 为准
+func MergeMetadata(ctx context.Context, md Metadata) context.Context {
+	if len(md) == 0 {
+		return ctx
+	}
+	out := MetadataFrom(ctx)
+	if out == nil {
+		out = make(Metadata, len(md))
+	}
+	for k, v := range md {
+		out[normalizeKey(k)] = v
+	}
+	return With(ctx, metadataKey, out)
+}
+
+// MetadataFrom 返回 ctx 中 Metadata 的副本，没有时返回 nil
+func MetadataFrom(ctx context.Context) Metadata {
+	md, _ := From(ctx, metadataKey)
+	return maps.Clone(md)
+}
+
+// MetadataValue 返回 ctx 中 key 对应的值
+func MetadataValue(ctx context.Context, key string) string {
+	md, _ := From(ctx, metadataKey)
+	return md[normalizeKey(key)]
+}
+
+// InjectHeader 把 m 写到 h 的 X-Md-<Key> 头
+func (m Metadata) InjectHeader(h http.Header) {
+	for k, v := range m {
+		h.Set(MetadataHeaderPrefix+k, url.PathEscape(v))
+	}
+}
+
+// MetadataFromHeader 读出 h 中的 X-Md-<Key> 头
+func MetadataFromHeader(h http.Header) Metadata {
+	var md Metadata
+	for name, vs := range h {
+		k, ok := strings.CutPrefix(http.CanonicalHeaderKey(name), MetadataHeaderPrefix)
+		if !ok || k == "" || len(vs) == 0 {
+			continue
+		}
+		if md == nil {
+			md = make(Metadata)
+		}
+		md[normalizeKey(k)] = unescape(vs[0])
+	}
+	return md
+}
+
+// Environ 返回 MD_<KEY>=value 形式的环境变量，按名字排序
+func (m Metadata) Environ() []string {
+	env := make([]string, 0, len(m))
+	for k, v := range m {
+		name := MetadataEnvPrefix + strings.ToUpper(strings.ReplaceAll(k, "-", "_"))
+		env = append(env, name+"="+url.PathEscape(v))
+	}
+	slices.Sort(env)
+	return env
+}
+
+// MetadataFromEnviron 读出 env 中 MD_<KEY>=value 形式的环境变量
+func MetadataFromEnviron(env []string) Metadata {
+	var md Metadata
+	for _, e := range env {
+		name, v, ok := strings.Cut(e, "=")
+		if !ok {
+			continue
+		}
+		k, ok := strings.CutPrefix(name, MetadataEnvPrefix)
+		if !ok || k == "" {
+			continue
+		}
+		if md == nil {
+			md = make(Metadata)
+		}
+		md[normalizeKey(k)] = unescape(v)
+	}
+	return md
+}
+
+// InjectCmd 把 ctx 中的 Metadata 写到 cmd 的环境变量，并去掉从当前进程继承的旧值
+func InjectCmd(ctx context.Context, cmd *exec.Cmd) {
+	env := slices.DeleteFunc(cmd.Environ(), func(e string) bool {
+		return strings.HasPrefix(e, MetadataEnvPrefix)
+	})
+	cmd.Env = append(env, MetadataFrom(ctx).Environ()...)
+}
+
+// MetadataHandler 把请求头中 key 在 allow 里的 Metadata 放进请求的 ctx，其余的丢弃。
+// 请求头由客户端任意填写，MDUser、MDTenant 这类表示身份的 key 只有在服务位于
+// 会清理 X-Md-* 请求头的可信代理之后时才能放进 allow，否则客户端可以冒充任意用户
+func MetadataHandler(next http.Handler, allow ...string) http.Handler {
+	allowed := make(map[string]bool, len(allow))
+	for _, k := range allow {
+		allowed[normalizeKey(k)] = true
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		md := MetadataFromHeader(r.Header)
+		maps.DeleteFunc(md, func(k, _ string) bool { return !allowed[k] })
+		if len(md) > 0 {
+			r = r.WithContext(MergeMetadata(r.Context(), md))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MetadataTransport 把请求 ctx 中的 Metadata 写到请求头
+type MetadataTransport struct {
+	Base http.RoundTripper // nil 时为 http.DefaultTransport
+}
+
+func (t *MetadataTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	md := MetadataFrom(req.Context())
+	if len(md) == 0 {
+		return base.RoundTrip(req)
+	}
+	// RoundTripper 不能修改传入的请求
+	req = req.Clone(req.Context())
+	md.InjectHeader(req.Header)
+	return base.RoundTrip(req)
+}
+
+func unescape(v string) string {
+	if s, err := url.PathUnescape(v); err == nil {
+		return s
+	}
+	return v
+}
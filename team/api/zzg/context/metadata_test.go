@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestWithMetadata(t *testing.T) {
+	ctx := WithMetadata(context.Background(), "Request_ID", "r-1", MDTenant, "acme")
+	child := WithMetadata(ctx, MDTenant, "other", MDUser, "bob")
+
+	if v := MetadataValue(ctx, MDTenant); v != "acme" {
+		t.Errorf("parent tenant = %q, child must not modify parent", v)
+	}
+	want := Metadata{MDRequestID: "r-1", MDTenant: "other", MDUser: "bob"}
+	if got := MetadataFrom(child); !maps.Equal(got, want) {
+		t.Errorf("MetadataFrom = %v, want %v", got, want)
+	}
+
+	// 返回的是副本
+	MetadataFrom(child)[MDUser] = "mallory"
+	if v := MetadataValue(child, MDUser); v != "bob" {
+		t.Errorf("user = %q, MetadataFrom must return a copy", v)
+	}
+	if MetadataFrom(context.Background()) != nil {
+		t.Error("MetadataFrom on empty ctx should be nil")
+	}
+}
+
+func TestMetadataHeaderAndEnv(t *testing.T) {
+	md := Metadata{MDRequestID: "r-1", MDUser: "a b\nc/ü"}
+	h := http.Header{}
+	md.InjectHeader(h)
+	if v := h.Get("X-Md-Request-Id"); v != "r-1" {
+		t.Errorf("header = %q, want r-1", v)
+	}
+	if got := MetadataFromHeader(h); !maps.Equal(got, md) {
+		t.Errorf("header round trip = %v, want %v", got, md)
+	}
+
+	env := md.Environ()
+	if env[0] != "MD_REQUEST_ID=r-1" || !strings.HasPrefix(env[1], "MD_USER=") {
+		t.Errorf("Environ = %q", env)
+	}
+	if got := MetadataFromEnviron(append(env, "PATH=/bin", "MD_=x")); !maps.Equal(got, md) {
+		t.Errorf("env round trip = %v, want %v", got, md)
+	}
+}
+
+func TestMetadataHTTP(t *testing.T) {
+	srv := httptest.NewServer(MetadataHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, MetadataValue(r.Context(), MDRequestID), ",", MetadataValue(r.Context(), MDTenant), ",", MetadataValue(r.Context(), MDUser))
+	}), MDRequestID, MDTenant))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &MetadataTransport{}}
+	// 不在 allow 里的 user 被丢弃，客户端不能借此冒充用户
+	ctx := WithMetadata(context.Background(), MDRequestID, "r-1", MDTenant, "acme", MDUser, "root")
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if string(body) != "r-1,acme," {
+		t.Errorf("server saw %q, want r-1,acme,", body)
+	}
+	if len(req.Header) != 0 {
+		t.Errorf("transport modified the caller's request: %v", req.Header)
+	}
+}
+
+// TestMetadataHelperProcess 是 TestInjectCmd 启动的子进程，打印收到的 Metadata
+func TestMetadataHelperProcess(t *testing.T) {
+	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
+		return
+	}
+	md := MetadataFromEnviron(os.Environ())
+	fmt.Print(md[MDRequestID], ",", md[MDUser], ",", len(md))
+	os.Exit(0)
+}
+
+func TestInjectCmd(t *testing.T) {
+	// 当前进程继承来的 MD_STALE 不应传给子进程
+	t.Setenv("MD_STALE", "x")
+	ctx := WithMetadata(context.Background(), MDRequestID, "r-1", MDUser, "a b")
+	cmd := exec.CommandContext(ctx, os.Args[0], "-test.run=^TestMetadataHelperProcess$")
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS=1")
+	InjectCmd(ctx, cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != "r-1,a b,2" {
+		t.Errorf("child saw %q, want r-1,a b,2", out)
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+/*
+	Pool 是感知 context 的任务池，语义同 errgroup：
+		1.最多同时运行 limit 个任务，Go 在没有空闲位置时阻塞；
+		2.任意一个任务返回错误后取消池的 context，正在运行的任务应通过 ctx.Done() 尽快退出，
+		  还没开始的任务不再运行，结果中的 Err 为 context.Cause(ctx)；
+		3.Wait 按提交顺序返回所有结果以及第一个错误，每个结果带开始时间和耗时。
+*/
+
+// Task 是池中运行的任务
+type Task[T any] func(ctx context.Context) (T, error)
+
+// Result 是一个任务的执行结果
+type Result[T any] struct {
+	Value    T
+	Err      error
+	Started  bool // 任务是否真正运行过
+	Start    time.Time
+	Duration time.Duration
+}
+
+// Pool 是任务池，请用 NewPool 创建，Wait 之后不能再提交任务
+type Pool[T any] struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	sem    chan struct{}
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	results []Result[T]
+	err     error
+}
+
+// NewPool 创建最多同时运行 limit 个任务的池，limit <= 0 表示不限制
+func NewPool[T any](ctx context.Context, limit int) *Pool[T] {
+	p := &Pool[T]{}
+	p.ctx, p.cancel = context.WithCancelCause(ctx)
+	if limit > 0 {
+		p.sem = make(chan struct{}, limit)
+	}
+	return p
+}
+
+// Context 返回池的 context，第一个错误发生后被取消
+func (p *Pool[T]) Context() context.Context {
+	return p.ctx
+}
+
+// Go 提交一个任务，返回它在结果中的下标
+func (p *Pool[T]) Go(task Task[T]) int {
+	p.mu.Lock()
+	i := len(p.results)
+	p.results = append(p.results, Result[T]{})
+	p.mu.Unlock()
+
+	if p.sem != nil {
+		select {
+		case p.sem <- struct{}{}:
+		case <-p.ctx.Done():
+			p.finish(i, Result[T]{Err: context.Cause(p.ctx)})
+			return i
+		}
+	}
+	if p.ctx.Err() != nil {
+		p.release()
+		p.finish(i, Result[T]{Err: context.Cause(p.ctx)})
+		return i
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer p.release()
+		start := time.Now()
+		v, err := task(p.ctx)
+		p.finish(i, Result[T]{Value: v, Err: err, Started: true, Start: start, Duration: time.Since(start)})
+	}()
+	return i
+}
+
+func (p *Pool[T]) release() {
+	if p.sem != nil {
+		<-p.sem
+	}
+}
+
+func (p *Pool[T]) finish(i int, r Result[T]) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.results[i] = r
+	if r.Started && r.Err != nil && p.err == nil {
+		p.err = r.Err
+		p.cancel(r.Err)
+	}
+}
+
+// Wait 等待所有任务结束，按提交顺序返回结果和第一个错误
+func (p *Pool[T]) Wait() ([]Result[T], error) {
+	p.wg.Wait()
+	p.cancel(context.Canceled)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		// 没有任务出错，但外层 context 结束导致有任务没有运行
+		for _, r := range p.results {
+			if r.Err != nil {
+				return p.results, r.Err
+			}
+		}
+	}
+	return p.results, p.err
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPoolOrderAndLimit(t *testing.T) {
+	p := NewPool[int](context.Background(), 2)
+	var running, peak atomic.Int32
+	for i := 0; i < 6; i++ {
+		p.Go(func(ctx context.Context) (int, error) {
+			n := running.Add(1)
+			for {
+				old := peak.Load()
+				if n <= old || peak.CompareAndSwap(old, n) {
+					break
+				}
+			}
+			time.Sleep(time.Duration(6-i) * time.Millisecond) // 后提交的先完成
+			running.Add(-1)
+			return i * i, nil
+		})
+	}
+	results, err := p.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if peak.Load() > 2 {
+		t.Errorf("peak concurrency = %d, want <= 2", peak.Load())
+	}
+	for i, r := range results {
+		if r.Value != i*i || !r.Started || r.Duration <= 0 || r.Start.IsZero() {
+			t.Errorf("result %d = %+v", i, r)
+		}
+	}
+}
+
+func TestPoolCancelOnFirstError(t *testing.T) {
+	boom := errors.New("boom")
+	p := NewPool[int](context.Background(), 1)
+	p.Go(func(ctx context.Context) (int, error) { return 1, nil })
+	p.Go(func(ctx context.Context) (int, error) { return 0, boom })
+	p.Go(func(ctx context.Context) (int, error) {
+		t.Error("task after the failure should not run")
+		return 0, nil
+	})
+	results, err := p.Wait()
+	if err != boom {
+		t.Fatalf("Wait err = %v, want boom", err)
+	}
+	if len(results) != 3 || results[0].Value != 1 || results[2].Started || !errors.Is(results[2].Err, boom) {
+		t.Errorf("results = %+v", results)
+	}
+	if context.Cause(p.Context()) != boom {
+		t.Errorf("Cause = %v, want boom", context.Cause(p.Context()))
+	}
+}
+
+func TestAddPool(t *testing.T) {
+	defer func(d time.Duration) { incDelay = d }(incDelay)
+	incDelay = 10 * time.Millisecond
+
+	start := time.Now()
+	res, err := AddPool(context.Background(), 6, 3)
+	if err != nil || res != 6 {
+		t.Fatalf("AddPool = %d, %v, want 6, nil", res, err)
+	}
+	if d := time.Since(start); d >= 6*incDelay {
+		t.Errorf("AddPool took %v, tasks did not run concurrently", d)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Millisecond)
+	defer cancel()
+	if _, err := AddPool(ctx, 6, 3); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("AddPool with timeout err = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ZcmOrg/demo-go-base/team/api/zzg/retry"
+)
+
+/*
+	Limiter 是令牌桶限流器，用来限制调用下游服务的频率：
+		1.桶里最多 burst 个令牌，每秒补充 rate 个，每次调用消耗一个；
+		2.Allow 不等待，没有令牌时返回 false；Reserve 预定一个令牌并返回需要等待的时间；
+		3.Wait 等到有令牌为止，和 Add1 里 select ctx.Done() 一样，ctx 结束时立即返回，
+		  预定的令牌退回桶里；按 ctx 的截止时间等不到令牌时不等待，直接返回 ErrLimitDeadline。
+	时间从 retry.Clock 取，测试用 retry.FakeClock。
+*/
+
+var (
+	// ErrLimitDeadline 表示在 ctx 的截止时间之前等不到令牌
+	ErrLimitDeadline = fmt.Errorf("ratelimit: wait would exceed context deadline: %w", context.DeadlineExceeded)
+	// ErrLimitBurst 表示永远等不到令牌，例如 burst < 1 或 rate <= 0 且桶已空
+	ErrLimitBurst = errors.New("ratelimit: tokens will never be available")
+)
+
+// Limiter 是令牌桶限流器，请用 NewLimiter 创建
+type Limiter struct {
+	rate  float64 // 每秒补充的令牌数
+	burst int
+	clock retry.Clock
+
+	mu        sync.Mutex
+	tokens    float64
+	last      time.Time
+	lastEvent time.Time // 最后一个预定的使用时间
+}
+
+// NewLimiter 创建每秒 rate 个、最多积攒 burst 个令牌的限流器，桶一开始是满的；clock 为 nil 时使用系统时间
+func NewLimiter(rate float64, burst int, clock retry.Clock) *Limiter {
+	if clock == nil {
+		clock = retry.RealClock
+	}
+	return &Limiter{rate: rate, burst: burst, clock: clock, tokens: float64(burst), last: clock.Now()}
+}
+
+// Reservation 是 Reserve 预定的令牌
+type Reservation struct {
+	l        *Limiter
+	ok       bool
+	act      time.Time // 可以使用令牌的时间
+	canceled bool
+}
+
+// OK 返回是否预定成功，失败时 Delay 没有意义
+func (r *Reservation) OK() bool {
+	return r.ok
+}
+
+// Delay 返回从现在起还需要等待的时间
+func (r *Reservation) Delay() time.Duration {
+	return max(r.act.Sub(r.l.clock.Now()), 0)
+}
+
+// Cancel 放弃预定，还没到使用时间的令牌退回桶里。
+// 之后的预定已经按这个令牌排好了时间，这部分不能再退回，否则两个调用方会分到同一个时间段
+func (r *Reservation) Cancel() {
+	if !r.ok {
+		return
+	}
+	l := r.l
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	if r.canceled || !now.Before(r.act) {
+		return
+	}
+	r.canceled = true
+	// r 之后的预定用掉的令牌
+	restore := 1 - l.lastEvent.Sub(r.act).Seconds()*l.rate
+	if restore <= 0 {
+		return
+	}
+	l.advance(now)
+	l.tokens = min(l.tokens+restore, float64(l.burst))
+	if r.act.Equal(l.lastEvent) && l.rate > 0 {
+		if prev := r.act.Add(-time.Duration(float64(time.Second) / l.rate)); !prev.Before(now) {
+			l.lastEvent = prev
+		}
+	}
+}
+
+// Allow 有令牌时消耗一个并返回 true，否则返回 false
+func (l *Limiter) Allow() bool {
+	return l.reserve(l.clock.Now(), 0).ok
+}
+
+// Reserve 预定一个令牌，调用方等待 Delay 之后再执行，不执行时调用 Cancel
+func (l *Limiter) Reserve() *Reservation {
+	return l.reserve(l.clock.Now(), math.MaxInt64)
+}
+
+// Wait 等到有令牌为止，ctx 结束时返回 context.Cause(ctx)
+func (l *Limiter) Wait(ctx context.Context) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	now := l.clock.Now()
+	maxWait := time.Duration(math.MaxInt64)
+	if deadline, ok := ctx.Deadline(); ok {
+		// ctx 的截止时间是真实时间，不能和 Clock 的时间相减，按剩余时间计算
+		maxWait = time.Until(deadline)
+	}
+	r := l.reserve(now, maxWait)
+	if !r.ok {
+		if maxWait < math.MaxInt64 && l.burst >= 1 && l.rate > 0 {
+			return ErrLimitDeadline
+		}
+		return ErrLimitBurst
+	}
+	d := r.act.Sub(now)
+	if d <= 0 {
+		return nil
+	}
+	if err := l.clock.Sleep(ctx, d); err != nil {
+		r.Cancel()
+		return err
+	}
+	return nil
+}
+
+// reserve 预定一个令牌，需要等待的时间超过 maxWait 时不预定
+func (l *Limiter) reserve(now time.Time, maxWait time.Duration) *Reservation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	r := &Reservation{l: l}
+	if l.burst < 1 {
+		return r
+	}
+	l.advance(now)
+	tokens := l.tokens - 1
+	var wait time.Duration
+	if tokens < 0 {
+		if l.rate <= 0 {
+			return r
+		}
+		secs := -tokens / l.rate
+		if secs*float64(time.Second) > float64(maxWait) {
+			return r
+		}
+		wait = time.Duration(secs * float64(time.Second))
+	}
+	l.tokens = tokens
+	r.ok = true
+	r.act = now.Add(wait)
+	l.lastEvent = r.act
+	return r
+}
+
+// advance 按经过的时间补充令牌
+func (l *Limiter) advance(now time.Time) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens = min(l.tokens+elapsed.Seconds()*l.rate, float64(l.burst))
+		l.last = now
+	}
+}
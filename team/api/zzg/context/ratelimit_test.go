@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/ZcmOrg/demo-go-base/team/api/zzg/retry"
+)
+
+func TestLimiterAllow(t *testing.T) {
+	clk := retry.NewFakeClock(time.Now())
+	l := NewLimiter(10, 3, clk)
+	for i := 0; i < 3; i++ {
+		if !l.Allow() {
+			t.Fatalf("Allow %d = false, want burst of 3", i)
+		}
+	}
+	if l.Allow() {
+		t.Fatal("Allow after burst = true")
+	}
+	clk.Advance(100 * time.Millisecond)
+	if !l.Allow() || l.Allow() {
+		t.Error("want exactly one token after 100ms at 10/s")
+	}
+	// 桶最多积攒 burst 个
+	clk.Advance(time.Hour)
+	n := 0
+	for l.Allow() {
+		n++
+	}
+	if n != 3 {
+		t.Errorf("tokens after an hour = %d, want 3", n)
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	clk := retry.NewFakeClock(time.Now())
+	l := NewLimiter(10, 1, clk)
+	r1, r2 := l.Reserve(), l.Reserve()
+	if !r1.OK() || r1.Delay() != 0 || !r2.OK() || r2.Delay() != 100*time.Millisecond {
+		t.Fatalf("delays = %v, %v, want 0, 100ms", r1.Delay(), r2.Delay())
+	}
+	r3 := l.Reserve()
+	if r3.Delay() != 200*time.Millisecond {
+		t.Fatalf("r3 delay = %v, want 200ms", r3.Delay())
+	}
+	// 取消 r3 后令牌退回，下一个预定同样等 200ms；重复取消没有影响
+	r3.Cancel()
+	r3.Cancel()
+	if d := l.Reserve().Delay(); d != 200*time.Millisecond {
+		t.Errorf("delay after cancel = %v, want 200ms", d)
+	}
+	// 已经到了使用时间的预定不再退回
+	clk.Advance(100 * time.Millisecond)
+	r2.Cancel()
+	if d := l.Reserve().Delay(); d != 200*time.Millisecond {
+		t.Errorf("delay after late cancel = %v, want 200ms", d)
+	}
+}
+
+func TestLimiterCancelMiddle(t *testing.T) {
+	clk := retry.NewFakeClock(time.Now())
+	l := NewLimiter(10, 1, clk)
+	l.Reserve()
+	r2, r3 := l.Reserve(), l.Reserve()
+	// r3 已经排在 r2 之后，取消 r2 退回的令牌已被 r3 用掉，r4 不能和 r3 同时执行
+	r2.Cancel()
+	r4 := l.Reserve()
+	if r3.Delay() != 200*time.Millisecond || r4.Delay() != 300*time.Millisecond {
+		t.Errorf("delays = %v, %v, want 200ms, 300ms", r3.Delay(), r4.Delay())
+	}
+	// 取消最后一个预定仍然退回令牌
+	r4.Cancel()
+	if d := l.Reserve().Delay(); d != 300*time.Millisecond {
+		t.Errorf("delay after cancelling the last = %v, want 300ms", d)
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	// FakeClock 的时间和 ctx 的真实截止时间无关
+	clk := retry.NewFakeClock(time.Unix(0, 0))
+	l := NewLimiter(10, 1, clk)
+	ctx := context.Background()
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := clk.Sleeps(); !slices.Equal(got, []time.Duration{100 * time.Millisecond}) {
+		t.Errorf("sleeps = %v, want [100ms]", got)
+	}
+
+	// 截止时间前等不到令牌：不等待、不消耗令牌
+	dctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(dctx); err != ErrLimitDeadline || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait = %v, want ErrLimitDeadline", err)
+	}
+	if len(clk.Sleeps()) != 1 {
+		t.Errorf("sleeps = %v, want no new sleep", clk.Sleeps())
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := l.Wait(cctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Wait = %v, want Canceled", err)
+	}
+	if d := l.Reserve().Delay(); d != 100*time.Millisecond {
+		t.Errorf("delay = %v, want 100ms, failed waits must not consume tokens", d)
+	}
+
+	if err := NewLimiter(10, 0, clk).Wait(ctx); err != ErrLimitBurst {
+		t.Errorf("Wait with burst 0 = %v, want ErrLimitBurst", err)
+	}
+}
+
+func TestLimiterWaitCanceledWhileSleeping(t *testing.T) {
+	l := NewLimiter(1, 1, nil)
+	l.Allow()
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Wait = %v, want Canceled", err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("Wait returned after %v, want prompt return on cancel", d)
+	}
+	// 预定的令牌已经退回，下一次仍然只需要等待不到 1 秒
+	if d := l.Reserve().Delay(); d > time.Second {
+		t.Errorf("delay = %v, want <= 1s", d)
+	}
+}
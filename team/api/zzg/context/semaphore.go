@@ -0,0 +1,116 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+/*
+	Semaphore 是带权重的信号量，用来限制同时占用的资源总量（例如连接数、内存）：
+		1.Acquire(ctx, n) 占用 n 个单位，不够时排队等待，ctx 结束时立即返回 context.Cause(ctx)；
+		2.等待者按先来后到的顺序获得资源，排在前面的大请求不会被后来的小请求饿死；
+		3.Release 归还资源并唤醒能满足的等待者。
+*/
+
+// Semaphore 是带权重的信号量，请用 NewSemaphore 创建
+type Semaphore struct {
+	size int64
+
+	mu      sync.Mutex
+	cur     int64
+	waiters list.List // *semWaiter，按到达顺序排列
+}
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{} // 获得资源后关闭
+}
+
+// NewSemaphore 创建总量为 size 的信号量
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire 占用 n 个单位，成功时返回 nil；n 超过总量时立即返回错误
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.mu.Lock()
+	if n > s.size {
+		s.mu.Unlock()
+		return fmt.Errorf("semaphore: acquire %d exceeds size %d", n, s.size)
+	}
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.mu.Unlock()
+		return nil
+	}
+	if ctx.Err() != nil {
+		s.mu.Unlock()
+		return context.Cause(ctx)
+	}
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-w.ready:
+			// ctx 结束的同时拿到了资源，归还后仍然返回错误
+			s.cur -= n
+			s.notify()
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 排在最前面的等待者走了，后面的可能已经可以满足
+			if front {
+				s.notify()
+			}
+		}
+		s.mu.Unlock()
+		return context.Cause(ctx)
+	}
+}
+
+// TryAcquire 不等待，资源足够且没有人排队时占用 n 个单位并返回 true
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release 归还 n 个单位，归还的比占用的多时 panic
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("semaphore: released more than held")
+	}
+	s.notify()
+}
+
+// notify 按顺序唤醒能满足的等待者，遇到第一个不能满足的就停止
+func (s *Semaphore) notify() {
+	for {
+		e := s.waiters.Front()
+		if e == nil {
+			return
+		}
+		w := e.Value.(*semWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(e)
+		close(w.ready)
+	}
+}
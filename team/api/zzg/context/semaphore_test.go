@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+)
+
+// waitQueued 等到信号量上有 n 个等待者
+func waitQueued(s *Semaphore, n int) {
+	for {
+		s.mu.Lock()
+		l := s.waiters.Len()
+		s.mu.Unlock()
+		if l == n {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+func TestSemaphoreAcquireRelease(t *testing.T) {
+	s := NewSemaphore(4)
+	ctx := context.Background()
+	if err := s.Acquire(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !s.TryAcquire(1) || s.TryAcquire(1) {
+		t.Fatal("TryAcquire should succeed once with 1 unit left")
+	}
+	if err := s.Acquire(ctx, 5); err == nil {
+		t.Error("Acquire(5) on size 4 should fail immediately")
+	}
+
+	got := make(chan error)
+	go func() { got <- s.Acquire(ctx, 2) }()
+	waitQueued(s, 1)
+	s.Release(1)
+	waitQueued(s, 1) // 只空出 1 个，仍在等待
+	s.Release(1)
+	if err := <-got; err != nil {
+		t.Fatal(err)
+	}
+	s.Release(4)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Release more than held should panic")
+		}
+	}()
+	s.Release(1)
+}
+
+func TestSemaphoreFIFO(t *testing.T) {
+	s := NewSemaphore(4)
+	ctx := context.Background()
+	s.Acquire(ctx, 3)
+
+	// 排在前面的 Acquire(2) 等待时，后来的小请求不能插队
+	big := make(chan error)
+	go func() { big <- s.Acquire(ctx, 2) }()
+	waitQueued(s, 1)
+	if s.TryAcquire(1) {
+		t.Fatal("TryAcquire jumped ahead of a queued waiter")
+	}
+	small := make(chan error)
+	go func() { small <- s.Acquire(ctx, 1) }()
+	waitQueued(s, 2)
+
+	s.Release(3) // 4 个空闲：big 拿 2，small 拿 1
+	if err := <-big; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-small; err != nil {
+		t.Fatal(err)
+	}
+	if !s.TryAcquire(1) || s.TryAcquire(1) {
+		t.Error("want exactly 1 unit left")
+	}
+}
+
+func TestSemaphoreCancel(t *testing.T) {
+	s := NewSemaphore(4)
+	s.Acquire(context.Background(), 3)
+
+	shutdown := errors.New("shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	big := make(chan error)
+	go func() { big <- s.Acquire(ctx, 2) }()
+	waitQueued(s, 1)
+	small := make(chan error)
+	go func() { small <- s.Acquire(context.Background(), 1) }()
+	waitQueued(s, 2)
+
+	// 排在最前面的等待者取消后，后面能满足的等待者立即获得资源
+	cancel(shutdown)
+	if err := <-big; err != shutdown {
+		t.Fatalf("Acquire = %v, want shutdown", err)
+	}
+	if err := <-small; err != nil {
+		t.Fatal(err)
+	}
+	if s.TryAcquire(1) {
+		t.Error("semaphore should be full")
+	}
+
+	// ctx 已经结束且需要等待时不排队
+	if err := s.Acquire(ctx, 1); err != shutdown {
+		t.Errorf("Acquire on done ctx = %v, want shutdown", err)
+	}
+	waitQueued(s, 0)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+/*
+	Add1/Add2 在取消时返回 -1，调用方分不清是超时还是手动取消，
+	而且每次都要等 inc 睡满一秒才检查 ctx。RunSteps 解决这几个问题：
+		1.每一步都拿到 ctx，阻塞的步骤用 Sleep 或 select ctx.Done() 等待，ctx 结束时立即返回；
+		2.ctx 结束时返回 *StepError，Err 为 ctx.Err()，可以区分 DeadlineExceeded 和 Canceled，
+		  Cause 保留 WithCancelCause/WithTimeoutCause 传入的原因；
+		3.返回已经完成的步数，调用方可以知道做到了哪一步。
+*/
+
+// Step 是可以被取消的一步，i 为从 0 开始的序号
+type Step func(ctx context.Context, i int) error
+
+// StepError 是 RunSteps 中途停止时返回的错误
+type StepError struct {
+	Done  int   // 已完成的步数，也就是失败步骤的序号
+	Total int   // 总步数
+	Err   error // ctx 结束时为 ctx.Err()，否则为步骤返回的错误
+	Cause error // ctx 带着原因结束时为 context.Cause(ctx)，否则为 nil
+}
+
+func (e *StepError) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("step %d/%d: %v: %v", e.Done+1, e.Total, e.Err, e.Cause)
+	}
+	return fmt.Sprintf("step %d/%d: %v", e.Done+1, e.Total, e.Err)
+}
+
+// Unwrap 使 errors.Is 对 Err 和 Cause 都能匹配
+func (e *StepError) Unwrap() []error {
+	if e.Cause != nil {
+		return []error{e.Err, e.Cause}
+	}
+	return []error{e.Err}
+}
+
+// Timeout 返回是否因为超时而停止
+func (e *StepError) Timeout() bool {
+	return errors.Is(e.Err, context.DeadlineExceeded)
+}
+
+// Canceled 返回是否因为被取消而停止
+func (e *StepError) Canceled() bool {
+	return errors.Is(e.Err, context.Canceled)
+}
+
+// RunSteps 依次执行 n 步，返回完成的步数；中途停止时返回 *StepError
+func RunSteps(ctx context.Context, n int, step Step) (int, error) {
+	for i := 0; i < n; i++ {
+		if ctx.Err() != nil {
+			return i, stepError(ctx, i, n, nil)
+		}
+		if err := step(ctx, i); err != nil {
+			return i, stepError(ctx, i, n, err)
+		}
+	}
+	return n, nil
+}
+
+func stepError(ctx context.Context, done, total int, err error) *StepError {
+	if ctx.Err() != nil {
+		e := &StepError{Done: done, Total: total, Err: ctx.Err()}
+		// 没有传入原因时 Cause(ctx) 就是 ctx.Err()；ctx.Err() 是可比较的哨兵错误，这里的比较不会 panic
+		if cause := context.Cause(ctx); cause != e.Err {
+			e.Cause = cause
+		}
+		return e
+	}
+	return &StepError{Done: done, Total: total, Err: err}
+}
+
+// Sleep 等待 d，ctx 先结束时立即返回 context.Cause(ctx)
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// AddSteps 是用 RunSteps 改写的 Add1：停止时返回已经累加的结果和 *StepError
+func AddSteps(ctx context.Context, a int) (int, error) {
+	res := 0
+	_, err := RunSteps(ctx, a, func(ctx context.Context, i int) error {
+		v, err := incCtx(ctx, res)
+		if err != nil {
+			return err
+		}
+		res = v
+		return nil
+	})
+	return res, err
+}
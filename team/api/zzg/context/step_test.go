@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunStepsTimeoutVsCancel(t *testing.T) {
+	defer func(d time.Duration) { incDelay = d }(incDelay)
+	incDelay = 100 * time.Millisecond
+
+	// 超时：第 3 步睡到一半被打断
+	ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	res, err := AddSteps(ctx, 10)
+	var se *StepError
+	if !errors.As(err, &se) || !se.Timeout() || se.Canceled() {
+		t.Fatalf("err = %v, want timeout StepError", err)
+	}
+	if res != 2 || se.Done != 2 || se.Total != 10 {
+		t.Errorf("res = %d, Done = %d/%d, want 2, 2/10", res, se.Done, se.Total)
+	}
+	if d := time.Since(start); d >= 3*incDelay {
+		t.Errorf("AddSteps returned after %v, step was not interrupted", d)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("errors.Is(err, DeadlineExceeded) = false")
+	}
+
+	// 手动取消
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	res, err = AddSteps(ctx, 1)
+	if !errors.As(err, &se) || !se.Canceled() || se.Timeout() || res != 0 {
+		t.Fatalf("res = %d, err = %v, want canceled StepError", res, err)
+	}
+}
+
+func TestRunStepsCause(t *testing.T) {
+	shutdown := errors.New("shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	done, err := RunSteps(ctx, 5, func(ctx context.Context, i int) error {
+		if i == 3 {
+			cancel(shutdown)
+			return Sleep(ctx, time.Hour)
+		}
+		return nil
+	})
+	if done != 3 {
+		t.Errorf("done = %d, want 3", done)
+	}
+	if !errors.Is(err, shutdown) || !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want both shutdown and Canceled", err)
+	}
+	if got, want := err.Error(), "step 4/5: context canceled: shutting down"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// sliceError 含有切片，不能用 == 比较
+type sliceError []string
+
+func (e sliceError) Error() string { return strings.Join(e, ", ") }
+
+func TestRunStepsStepError(t *testing.T) {
+	bad := errors.New("bad input")
+	done, err := RunSteps(context.Background(), 3, func(ctx context.Context, i int) error {
+		if i == 1 {
+			return bad
+		}
+		return nil
+	})
+	var se *StepError
+	if !errors.As(err, &se) || se.Err != bad || se.Timeout() || se.Canceled() || done != 1 {
+		t.Errorf("done = %d, err = %#v", done, err)
+	}
+	if se.Cause != nil {
+		t.Errorf("Cause = %v, want nil when ctx did not end", se.Cause)
+	}
+
+	// 步骤返回不可比较的错误时不会 panic
+	_, err = RunSteps(context.Background(), 1, func(context.Context, int) error { return sliceError{"a", "b"} })
+	if got, want := err.Error(), "step 1/1: a, b"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if !errors.As(err, new(sliceError)) {
+		t.Error("errors.As(err, sliceError) = false")
+	}
+
+	if done, err := RunSteps(context.Background(), 3, func(context.Context, int) error { return nil }); done != 3 || err != nil {
+		t.Errorf("RunSteps = %d, %v, want 3, nil", done, err)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+)
+
+/*
+	用字符串做 context key 容易和别的包冲突，取值时还要做类型断言。Key[T] 解决这两个问题：
+		1.每个 NewKey 返回的指针都是唯一的 key，名字只用于打印；
+		2.With/From 带类型，From 不需要断言，值不存在时返回零值和 false；
+		3.后台任务需要请求里的值，但不能随请求一起被取消：Detach 保留全部值，
+		  DetachWith 只复制 Metadata 和指定的 key，不持有原 ctx 的其它内容。
+*/
+
+// Key 是带类型的 context key，请用 NewKey 创建
+type Key[T any] struct {
+	name string
+}
+
+// NewKey 创建名为 name 的 key
+func NewKey[T any](name string) *Key[T] {
+	return &Key[T]{name: name}
+}
+
+func (k *Key[T]) String() string {
+	return "context key " + k.name
+}
+
+// With 返回带有 k=v 的 ctx
+func With[T any](ctx context.Context, k *Key[T], v T) context.Context {
+	return context.WithValue(ctx, k, v)
+}
+
+// From 取出 k 的值，不存在时返回零值和 false
+func From[T any](ctx context.Context, k *Key[T]) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// ValueKey 是可以被 DetachWith 复制的 key，*Key[T] 都实现了它
+type ValueKey interface {
+	copyValue(dst, src context.Context) context.Context
+}
+
+func (k *Key[T]) copyValue(dst, src context.Context) context.Context {
+	if v, ok := From(src, k); ok {
+		return With(dst, k, v)
+	}
+	return dst
+}
+
+// Detach 返回不会随 ctx 取消、没有截止时间，但保留 ctx 全部值的 context
+func Detach(ctx context.Context) context.Context {
+	return context.WithoutCancel(ctx)
+}
+
+// DetachWith 返回一个新的根 context，只带 ctx 的 Metadata 和 keys 中的值
+func DetachWith(ctx context.Context, keys ...ValueKey) context.Context {
+	out := context.Background()
+	if md := MetadataFrom(ctx); len(md) > 0 {
+		out = With(out, metadataKey, md)
+	}
+	for _, k := range keys {
+		out = k.copyValue(out, ctx)
+	}
+	return out
+}
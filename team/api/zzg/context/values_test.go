@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestKeyWithFrom(t *testing.T) {
+	userID := NewKey[int]("user id")
+	other := NewKey[int]("user id") // 同名的 key 互不影响
+	ctx := With(context.Background(), userID, 42)
+
+	if v, ok := From(ctx, userID); !ok || v != 42 {
+		t.Errorf("From = %d, %t, want 42, true", v, ok)
+	}
+	if v, ok := From(ctx, other); ok || v != 0 {
+		t.Errorf("From other key = %d, %t, want 0, false", v, ok)
+	}
+	if s := userID.String(); s != "context key user id" {
+		t.Errorf("String() = %q", s)
+	}
+}
+
+func TestDetach(t *testing.T) {
+	traceID := NewKey[string]("trace id")
+	body := NewKey[[]byte]("body")
+	ctx := With(context.Background(), traceID, "t-1")
+	ctx = With(ctx, body, make([]byte, 1<<20))
+	ctx = WithMetadata(ctx, MDRequestID, "r-1")
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+	cancel()
+
+	d := Detach(ctx)
+	if d.Err() != nil || d.Done() != nil {
+		t.Error("Detach should not be canceled with its parent")
+	}
+	if _, ok := d.Deadline(); ok {
+		t.Error("Detach should drop the deadline")
+	}
+	if v, _ := From(d, traceID); v != "t-1" {
+		t.Errorf("Detach lost value, trace id = %q", v)
+	}
+
+	d = DetachWith(ctx, traceID)
+	if d.Err() != nil {
+		t.Error("DetachWith should not be canceled with its parent")
+	}
+	if v, _ := From(d, traceID); v != "t-1" {
+		t.Errorf("trace id = %q, want t-1", v)
+	}
+	if _, ok := From(d, body); ok {
+		t.Error("DetachWith copied a key that was not listed")
+	}
+	if v := MetadataValue(d, MDRequestID); v != "r-1" {
+		t.Errorf("request id = %q, want r-1", v)
+	}
+}
@@ -0,0 +1,215 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+/*
+	调用 Add1 这类接口时，各处都手写了重试循环，而且都不看 ctx 的截止时间。
+	Do 按 Policy 重试，并且遵守 ctx：
+		1.指数退避：第 n 次重试前等待 Initial*Multiplier^(n-1)，不超过 Max，再按 Jitter 随机缩短；
+		2.MaxAttempts 限制总尝试次数，Retryable 判断错误是否值得重试，Permanent 包装的错误不重试；
+		3.ctx 有截止时间且设置了 MaxAttempts 时，每次尝试分到剩余时间的 1/剩余次数，
+		  单次尝试超时不会用光整个预算；等待时间已经超过剩余时间时直接放弃，不再空等；
+		4.时间都从 Clock 取，测试用 FakeClock，不需要真正等待。
+*/
+
+// Policy 是重试策略，零值表示不等待、不限次数地重试
+type Policy struct {
+	MaxAttempts int           // 最多尝试次数（含第一次），<= 0 表示不限制，直到 ctx 结束
+	Initial     time.Duration // 第一次重试前的等待
+	Max         time.Duration // 单次等待的上限，<= 0 表示不限制
+	Multiplier  float64       // 每次重试后等待时间的倍数，<= 0 时为 2
+	Jitter      float64       // 0~1，实际等待在 [d*(1-Jitter), d] 之间随机
+
+	// Retryable 判断错误是否可以重试，nil 时除了 Permanent 和 context.Canceled 都重试
+	Retryable func(error) bool
+
+	Clock Clock          // nil 时为 RealClock
+	Rand  func() float64 // 返回 [0,1) 的随机数，nil 时为 rand.Float64
+}
+
+// Default 是一般远程调用的重试策略
+var Default = Policy{
+	MaxAttempts: 5,
+	Initial:     100 * time.Millisecond,
+	Max:         5 * time.Second,
+	Multiplier:  2,
+	Jitter:      0.5,
+}
+
+// Func 是被重试的调用，attempt 从 0 开始
+type Func func(ctx context.Context, attempt int) error
+
+// Error 是放弃重试时返回的错误
+type Error struct {
+	Attempts int   // 已经尝试的次数
+	Err      error // 最后一次尝试的错误，还没尝试过时为 nil
+	Cause    error // 因为 ctx 结束或剩余时间不够而放弃时为原因，否则为 nil
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Cause == nil:
+		return fmt.Sprintf("retry: %d attempts: %v", e.Attempts, e.Err)
+	case e.Err == nil:
+		return fmt.Sprintf("retry: %d attempts: %v", e.Attempts, e.Cause)
+	default:
+		return fmt.Sprintf("retry: %d attempts: %v (last error: %v)", e.Attempts, e.Cause, e.Err)
+	}
+}
+
+// Unwrap 使 errors.Is 对最后的错误和放弃原因都能匹配
+func (e *Error) Unwrap() []error {
+	var errs []error
+	for _, err := range []error{e.Err, e.Cause} {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+type permanent struct{ err error }
+
+func (p *permanent) Error() string { return p.err.Error() }
+func (p *permanent) Unwrap() error { return p.err }
+
+// Permanent 包装不应重试的错误，例如参数错误
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanent{err}
+}
+
+// IsPermanent 返回 err 是否被 Permanent 包装过
+func IsPermanent(err error) bool {
+	var p *permanent
+	return errors.As(err, &p)
+}
+
+// Do 按策略调用 fn，直到成功或放弃；放弃时返回 *Error
+func Do(ctx context.Context, p Policy, fn Func) error {
+	_, err := DoValue(ctx, p, func(ctx context.Context, attempt int) (struct{}, error) {
+		return struct{}{}, fn(ctx, attempt)
+	})
+	return err
+}
+
+// DoValue 同 Do，返回成功那次调用的结果
+func DoValue[T any](ctx context.Context, p Policy, fn func(ctx context.Context, attempt int) (T, error)) (T, error) {
+	p = p.withDefaults()
+	var zero T
+	var last error
+	wait := p.Initial
+	deadline, hasDeadline := p.deadline(ctx)
+	for attempt := 0; ; attempt++ {
+		if cause := p.expired(ctx, deadline, hasDeadline); cause != nil {
+			return zero, &Error{Attempts: attempt, Err: last, Cause: cause}
+		}
+		actx, cancel := p.attemptContext(ctx, deadline, hasDeadline, attempt)
+		v, err := fn(actx, attempt)
+		cancel()
+		if err == nil {
+			return v, nil
+		}
+		last = err
+		n := attempt + 1
+
+		// 外层 ctx 结束时不再重试；只是这次尝试分到的时间用完了则可以重试
+		if cause := p.expired(ctx, deadline, hasDeadline); cause != nil {
+			return zero, &Error{Attempts: n, Err: err, Cause: cause}
+		}
+		if !p.Retryable(err) || (p.MaxAttempts > 0 && n >= p.MaxAttempts) {
+			return zero, &Error{Attempts: n, Err: err}
+		}
+
+		d := p.backoff(wait)
+		if hasDeadline && !p.Clock.Now().Add(d).Before(deadline) {
+			return zero, &Error{Attempts: n, Err: err, Cause: context.DeadlineExceeded}
+		}
+		if err := p.Clock.Sleep(ctx, d); err != nil {
+			return zero, &Error{Attempts: n, Err: last, Cause: err}
+		}
+		wait = p.next(wait)
+	}
+}
+
+func (p Policy) withDefaults() Policy {
+	if p.Multiplier <= 0 {
+		p.Multiplier = 2
+	}
+	if p.Retryable == nil {
+		p.Retryable = defaultRetryable
+	} else {
+		retryable := p.Retryable
+		p.Retryable = func(err error) bool { return !IsPermanent(err) && retryable(err) }
+	}
+	if p.Clock == nil {
+		p.Clock = RealClock
+	}
+	if p.Rand == nil {
+		p.Rand = rand.Float64
+	}
+	return p
+}
+
+func defaultRetryable(err error) bool {
+	return !IsPermanent(err) && !errors.Is(err, context.Canceled)
+}
+
+// deadline 把 ctx 的截止时间换算到 Clock 的时间上：ctx 的截止时间是真实时间，
+// 按开始时的剩余时间从 Clock 的当前时间算起，之后只和 Clock 的时间比较
+func (p Policy) deadline(ctx context.Context) (time.Time, bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return time.Time{}, false
+	}
+	return p.Clock.Now().Add(time.Until(d)), true
+}
+
+// expired 返回 ctx 是否已经结束，或者按 Clock 的时间已经过了截止时间
+func (p Policy) expired(ctx context.Context, deadline time.Time, ok bool) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	if ok && !p.Clock.Now().Before(deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// attemptContext 为第 attempt 次尝试分配剩余时间的一份
+func (p Policy) attemptContext(ctx context.Context, deadline time.Time, ok bool, attempt int) (context.Context, context.CancelFunc) {
+	if !ok || p.MaxAttempts <= 0 {
+		return ctx, func() {}
+	}
+	share := deadline.Sub(p.Clock.Now()) / time.Duration(p.MaxAttempts-attempt)
+	return context.WithTimeout(ctx, share)
+}
+
+// backoff 返回加上随机抖动后的等待时间
+func (p Policy) backoff(wait time.Duration) time.Duration {
+	if p.Max > 0 {
+		wait = min(wait, p.Max)
+	}
+	j := min(max(p.Jitter, 0), 1)
+	return time.Duration(float64(wait) * (1 - j*p.Rand()))
+}
+
+func (p Policy) next(wait time.Duration) time.Duration {
+	f := float64(wait) * p.Multiplier
+	if p.Max > 0 && f > float64(p.Max) {
+		return p.Max
+	}
+	if f >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(f)
+}
@@ -0,0 +1,178 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+var errFlaky = errors.New("flaky")
+
+func TestBackoff(t *testing.T) {
+	clk := NewFakeClock(time.Now())
+	p := Policy{MaxAttempts: 5, Initial: 100 * time.Millisecond, Max: 500 * time.Millisecond, Clock: clk}
+	calls := 0
+	err := Do(context.Background(), p, func(ctx context.Context, attempt int) error {
+		if attempt != calls {
+			t.Errorf("attempt = %d, want %d", attempt, calls)
+		}
+		calls++
+		return errFlaky
+	})
+	var re *Error
+	if !errors.As(err, &re) || re.Attempts != 5 || re.Cause != nil || !errors.Is(err, errFlaky) {
+		t.Fatalf("err = %v, want 5 attempts of flaky", err)
+	}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 500 * time.Millisecond}
+	if got := clk.Sleeps(); !slices.Equal(got, want) {
+		t.Errorf("sleeps = %v, want %v", got, want)
+	}
+}
+
+func TestJitter(t *testing.T) {
+	clk := NewFakeClock(time.Now())
+	p := Policy{MaxAttempts: 2, Initial: 100 * time.Millisecond, Jitter: 0.5, Clock: clk, Rand: func() float64 { return 0.5 }}
+	Do(context.Background(), p, func(context.Context, int) error { return errFlaky })
+	if got := clk.Sleeps(); !slices.Equal(got, []time.Duration{75 * time.Millisecond}) {
+		t.Errorf("sleeps = %v, want [75ms]", got)
+	}
+}
+
+func TestBudgetSplit(t *testing.T) {
+	// FakeClock 的时间和 ctx 的真实截止时间无关，剩余时间只按 Clock 经过的时间扣减
+	clk := NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithTimeout(context.Background(), 900*time.Millisecond)
+	defer cancel()
+
+	// 每次尝试耗时 100ms，前两次因为分到的时间用完而失败
+	var budgets []time.Duration
+	v, err := DoValue(ctx, Policy{MaxAttempts: 3, Clock: clk}, func(ctx context.Context, attempt int) (int, error) {
+		if ctx.Err() != nil {
+			t.Errorf("attempt %d got an expired context", attempt)
+		}
+		deadline, _ := ctx.Deadline()
+		budgets = append(budgets, time.Until(deadline).Round(10*time.Millisecond))
+		clk.Advance(100 * time.Millisecond)
+		if attempt < 2 {
+			return 0, context.DeadlineExceeded
+		}
+		return 42, nil
+	})
+	if v != 42 || err != nil {
+		t.Fatalf("DoValue = %d, %v, want 42, nil", v, err)
+	}
+	want := []time.Duration{300 * time.Millisecond, 400 * time.Millisecond, 700 * time.Millisecond}
+	if !slices.Equal(budgets, want) {
+		t.Errorf("budgets = %v, want %v", budgets, want)
+	}
+}
+
+func TestGiveUpBeforeDeadline(t *testing.T) {
+	clk := NewFakeClock(time.Unix(0, 0))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// 下一次等待 2s 已经超过剩余的 1s，直接放弃
+	err := Do(ctx, Policy{Initial: 2 * time.Second, Clock: clk}, func(context.Context, int) error { return errFlaky })
+	var re *Error
+	if !errors.As(err, &re) || re.Attempts != 1 || !errors.Is(err, context.DeadlineExceeded) || !errors.Is(err, errFlaky) {
+		t.Fatalf("err = %v, want 1 attempt and DeadlineExceeded", err)
+	}
+	if len(clk.Sleeps()) != 0 {
+		t.Errorf("sleeps = %v, want none", clk.Sleeps())
+	}
+
+	// 按 Clock 的时间已经过了截止时间，不再重试
+	calls := 0
+	err = Do(ctx, Policy{Clock: clk}, func(context.Context, int) error {
+		calls++
+		clk.Advance(time.Second)
+		return errFlaky
+	})
+	if !errors.As(err, &re) || calls != 1 || re.Attempts != 1 || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v after %d calls, want 1 attempt and DeadlineExceeded", err, calls)
+	}
+
+	// ctx 已经结束，一次也不尝试
+	cancel()
+	err = Do(ctx, Policy{Clock: clk}, func(context.Context, int) error {
+		t.Error("fn called after cancel")
+		return nil
+	})
+	if !errors.As(err, &re) || re.Attempts != 0 || re.Err != nil || !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want 0 attempts and Canceled", err)
+	}
+}
+
+func TestNotRetryable(t *testing.T) {
+	clk := NewFakeClock(time.Now())
+	bad := errors.New("bad request")
+	calls := 0
+	err := Do(context.Background(), Policy{Clock: clk}, func(context.Context, int) error {
+		calls++
+		return Permanent(bad)
+	})
+	if calls != 1 || !errors.Is(err, bad) || !IsPermanent(err) {
+		t.Errorf("calls = %d, err = %v, want 1 call and permanent bad", calls, err)
+	}
+
+	calls = 0
+	p := Policy{Clock: clk, Retryable: func(err error) bool { return errors.Is(err, errFlaky) }}
+	err = Do(context.Background(), p, func(context.Context, int) error {
+		calls++
+		if calls < 3 {
+			return errFlaky
+		}
+		return bad
+	})
+	if calls != 3 || !errors.Is(err, bad) {
+		t.Errorf("calls = %d, err = %v, want 3 calls and bad", calls, err)
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) != nil")
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	clk := NewFakeClock(time.Now())
+	shutdown := errors.New("shutting down")
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+	err := Do(ctx, Policy{Clock: clk}, func(ctx context.Context, attempt int) error {
+		if attempt == 2 {
+			cancel(shutdown)
+		}
+		return errFlaky
+	})
+	var re *Error
+	if !errors.As(err, &re) || re.Attempts != 3 || re.Cause != shutdown {
+		t.Fatalf("err = %v, want 3 attempts caused by shutdown", err)
+	}
+	if got, want := err.Error(), "retry: 3 attempts: shutting down (last error: flaky)"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestRealClock(t *testing.T) {
+	start := time.Now()
+	err := Do(context.Background(), Policy{MaxAttempts: 3, Initial: time.Millisecond}, func(ctx context.Context, attempt int) error {
+		if attempt < 2 {
+			return errFlaky
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d < 3*time.Millisecond {
+		t.Errorf("Do returned after %v, want >= 3ms of backoff", d)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := RealClock.Sleep(ctx, time.Hour); !errors.Is(err, context.Canceled) {
+		t.Errorf("Sleep = %v, want Canceled", err)
+	}
+}